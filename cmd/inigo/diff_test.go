@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// unifiedDiff
+// ---------------------------------------------------------------------------
+
+func TestUnifiedDiff_Equal(t *testing.T) {
+	if got := unifiedDiff("a", "b", []byte("x\n"), []byte("x\n")); got != "" {
+		t.Errorf("unifiedDiff of equal input = %q, want empty", got)
+	}
+}
+
+func TestUnifiedDiff_SingleHunk(t *testing.T) {
+	a := "one\ntwo\nthree\n"
+	b := "one\n2\nthree\nfour\n"
+	want := `--- a.conf
++++ b.conf
+@@ -1,3 +1,4 @@
+ one
+-two
++2
+ three
++four
+`
+	if got := unifiedDiff("a.conf", "b.conf", []byte(a), []byte(b)); got != want {
+		t.Errorf("unifiedDiff mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	var a, b []string
+	for i := range 20 {
+		line := strings.Repeat("x", i+1)
+		a = append(a, line)
+		switch i {
+		case 1:
+			b = append(b, "changed")
+		case 17:
+			// deleted
+		default:
+			b = append(b, line)
+		}
+	}
+	got := unifiedDiff("a", "b", []byte(strings.Join(a, "\n")+"\n"), []byte(strings.Join(b, "\n")+"\n"))
+	if n := strings.Count(got, "@@ -"); n != 2 {
+		t.Fatalf("expected 2 hunks, got %d:\n%s", n, got)
+	}
+	if !strings.Contains(got, "@@ -1,5 +1,5 @@") || !strings.Contains(got, "@@ -15,6 +15,5 @@") {
+		t.Errorf("unexpected hunk headers:\n%s", got)
+	}
+}
+
+func TestUnifiedDiff_FromEmpty(t *testing.T) {
+	want := "--- a\n+++ b\n@@ -0,0 +1 @@\n+k = v\n"
+	if got := unifiedDiff("a", "b", nil, []byte("k = v\n")); got != want {
+		t.Errorf("unifiedDiff = %q, want %q", got, want)
+	}
+}
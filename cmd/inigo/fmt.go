@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thesmart/inigo/pgini"
+)
+
+var (
+	fmtCheck bool
+	fmtDiff  bool
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [flags] <ini-file>...",
+	Short: "Rewrite INI files in canonical style",
+	Long: `Rewrite INI files in place in one canonical style:
+
+  - "key = value" with the '=' of consecutive params aligned
+  - values quoted only when needed
+  - lowercase [section] headers and include directives
+  - '#' comments, no stray whitespace, single blank lines
+
+Comments, keys, values, and include directives are kept. Included files are
+not followed; pass them as arguments to format them too.
+
+With --check, files are not written; their names are listed if they need
+formatting, and fmt exits with status 1. With --diff, files are not written;
+the changes are printed as a unified diff.`,
+	Example: `  # Format files in place
+  inigo fmt /etc/myapp/*.conf
+
+  # Fail a CI job when a file is not formatted, showing what would change
+  inigo fmt --check --diff conf/*.conf`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runFmt,
+}
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "list files that need formatting and exit 1 if any, without writing")
+	fmtCmd.Flags().BoolVar(&fmtDiff, "diff", false, "print changes as a unified diff, without writing")
+}
+
+func runFmt(cmd *cobra.Command, args []string) error {
+	unformatted := 0
+	for _, iniFile := range args {
+		src, err := os.ReadFile(iniFile)
+		if err != nil {
+			return err
+		}
+		formatted, err := pgini.Format(iniFile, src)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(src, formatted) {
+			continue
+		}
+		unformatted++
+
+		if fmtCheck && !fmtDiff {
+			fmt.Fprintln(cmd.OutOrStdout(), iniFile)
+		}
+		if fmtDiff {
+			fmt.Fprint(cmd.OutOrStdout(), unifiedDiff(iniFile+".orig", iniFile, src, formatted))
+		}
+		if !fmtCheck && !fmtDiff {
+			if err := writeFileAtomic(iniFile, formatted); err != nil {
+				return err
+			}
+		}
+	}
+
+	if fmtCheck && unformatted > 0 {
+		if !silent {
+			fmt.Fprintf(os.Stderr, "inigo: %d file(s) need formatting\n", unformatted)
+		}
+		os.Exit(1)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/thesmart/inigo/pgini"
+)
+
+// exitNotFound is the exit code of get when the section or key is absent.
+const exitNotFound = 2
+
+var (
+	getDefault string
+	getType    string
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get [flags] <ini-file> [section] <key>",
+	Short: "Print a single INI param value",
+	Long: `Print the decoded (unescaped) value of a single parameter.
+
+If no section is given, the default (unnamed) section is used.
+If the section or key is absent, get exits with status 2, unless --default
+is given, in which case the default is printed instead. Other errors exit
+with status 1.
+
+With --type, the value is normalized using the same rules as struct
+decoding: bool prints true or false, int prints a base-10 integer, and
+float prints a decimal number. A value that does not parse is an error.`,
+	Example: `  # Print a value from a named section
+  inigo get pg_service.conf mydb host
+
+  # Print a value from the default section, with a fallback
+  inigo get --default 8080 app.conf port
+
+  # Normalize on/off/yes/no/1/0 for shell tests
+  if [ "$(inigo get --type bool app.conf debug)" = true ]; then ...; fi`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: runGet,
+}
+
+func init() {
+	getCmd.Flags().StringVarP(&getDefault, "default", "d", "",
+		"value to print when the section or key is absent")
+	getCmd.Flags().StringVarP(&getType, "type", "t", "",
+		"normalize the value as one of: bool, int, float")
+}
+
+func runGet(cmd *cobra.Command, args []string) error {
+	iniFile, section, key := args[0], "", args[len(args)-1]
+	if len(args) == 3 {
+		section = args[1]
+	}
+
+	if err := checkType(getType); err != nil {
+		return err
+	}
+
+	cfg, err := pgini.Parse(iniFile)
+	if err != nil {
+		return err
+	}
+
+	var param *pgini.Param
+	if sec := cfg.GetSection(section); sec != nil {
+		param, _ = sec.GetParam(key)
+	}
+	if param == nil {
+		if !cmd.Flags().Changed("default") {
+			if !silent {
+				fmt.Fprintf(os.Stderr, "inigo: %s not found in %s\n", paramLabel(section, key), iniFile)
+			}
+			os.Exit(exitNotFound)
+		}
+		param = &pgini.Param{Name: key, Value: getDefault}
+	}
+
+	value, err := formatValue(param, getType)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), value)
+	return nil
+}
+
+// checkType returns an error if typ is not a supported --type name.
+func checkType(typ string) error {
+	switch typ {
+	case "", "bool", "int", "float":
+		return nil
+	default:
+		return fmt.Errorf("unsupported --type %q: must be one of bool, int, float", typ)
+	}
+}
+
+// formatValue returns the value of p normalized as typ. An empty typ returns
+// the value unchanged.
+func formatValue(p *pgini.Param, typ string) (string, error) {
+	switch typ {
+	case "":
+		return p.Value, nil
+	case "bool":
+		b, err := p.Bool()
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(b), nil
+	case "int":
+		n, err := p.Int()
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(n, 10), nil
+	case "float":
+		f, err := p.Float()
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	default:
+		return "", checkType(typ)
+	}
+}
+
+// paramLabel names a key for messages, qualified by its section if any.
+func paramLabel(section, key string) string {
+	if section == "" {
+		return fmt.Sprintf("key %q", key)
+	}
+	return fmt.Sprintf("key %q in section %q", key, section)
+}
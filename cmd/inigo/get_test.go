@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/thesmart/inigo/pgini"
+)
+
+// ---------------------------------------------------------------------------
+// formatValue
+// ---------------------------------------------------------------------------
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value string
+		typ   string
+		want  string
+	}{
+		{"it's here", "", "it's here"},
+		{"on", "bool", "true"},
+		{"NO", "bool", "false"},
+		{"0x10", "int", "16"},
+		{"2.9", "int", "2"},
+		{"1e3", "float", "1000"},
+		{"0.25", "float", "0.25"},
+	}
+	for _, tt := range tests {
+		got, err := formatValue(&pgini.Param{Name: "k", Value: tt.value}, tt.typ)
+		if err != nil {
+			t.Errorf("formatValue(%q, %q) error: %v", tt.value, tt.typ, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("formatValue(%q, %q) = %q, want %q", tt.value, tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestFormatValue_Errors(t *testing.T) {
+	tests := []struct {
+		value string
+		typ   string
+	}{
+		{"maybe", "bool"},
+		{"lots", "int"},
+		{"", "float"},
+		{"1", "string"},
+	}
+	for _, tt := range tests {
+		if _, err := formatValue(&pgini.Param{Name: "k", Value: tt.value}, tt.typ); err == nil {
+			t.Errorf("formatValue(%q, %q) expected error", tt.value, tt.typ)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// checkType
+// ---------------------------------------------------------------------------
+
+func TestCheckType(t *testing.T) {
+	for _, typ := range []string{"", "bool", "int", "float"} {
+		if err := checkType(typ); err != nil {
+			t.Errorf("checkType(%q) error: %v", typ, err)
+		}
+	}
+	if err := checkType("Bool"); err == nil {
+		t.Error("checkType(\"Bool\") expected error")
+	}
+}
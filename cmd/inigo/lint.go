@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thesmart/inigo/pgini"
+)
+
+// Exit codes of lint, by the highest severity found.
+const (
+	exitLintWarning = 1
+	exitLintError   = 2
+)
+
+var lintFormat string
+
+var lintCmd = &cobra.Command{
+	Use:     "lint [flags] <ini-file>...",
+	Aliases: []string{"validate"},
+	Short:   "Report likely mistakes in INI files",
+	Long: `Parse INI files, following includes, and report problems:
+
+  error    parse-error       syntax or include errors
+  warning  shadowed-key      a key defined again later in the same file
+  info     shadowed-key      a key overridden by another file
+  warning  reopened-section  a section header repeated in the same file
+  info     reopened-section  a section reopened by another file
+  info     empty-section     a section without params
+  warning  missing-include   an include_if_exists target that does not exist
+  warning  key-case          a key spelled with different letter case
+  warning  needs-quoting     an unquoted value cut short by '#' or ';'
+
+Exit status is 0 when nothing worse than info is found, 1 for warnings, and
+2 for errors.`,
+	Example: `  # Lint a config and everything it includes
+  inigo lint /etc/myapp.conf
+
+  # Annotate a GitHub Actions run
+  inigo lint --format github conf/*.conf
+
+  # Machine-readable output
+  inigo lint --format json app.conf | jq '.[] | select(.severity == "error")'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runLint,
+}
+
+func init() {
+	lintCmd.Flags().StringVarP(&lintFormat, "format", "f", "text",
+		"output format: text, json, or github")
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	switch lintFormat {
+	case "text", "json", "github":
+	default:
+		return fmt.Errorf("unsupported --format %q: must be one of text, json, github", lintFormat)
+	}
+
+	var diags []pgini.Diagnostic
+	for _, iniFile := range args {
+		d, err := pgini.Lint(iniFile)
+		if err != nil {
+			return err
+		}
+		diags = append(diags, d...)
+	}
+	for i := range diags {
+		diags[i].Path = displayPath(diags[i].Path)
+	}
+
+	if err := writeDiagnostics(cmd.OutOrStdout(), lintFormat, diags); err != nil {
+		return err
+	}
+
+	worst := pgini.SeverityInfo
+	for _, d := range diags {
+		worst = max(worst, d.Severity)
+	}
+	switch worst {
+	case pgini.SeverityError:
+		os.Exit(exitLintError)
+	case pgini.SeverityWarning:
+		os.Exit(exitLintWarning)
+	}
+	return nil
+}
+
+// lintJSON is the JSON form of a pgini.Diagnostic.
+type lintJSON struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+// writeDiagnostics writes diags to w in the given format.
+func writeDiagnostics(w io.Writer, format string, diags []pgini.Diagnostic) error {
+	switch format {
+	case "json":
+		out := make([]lintJSON, len(diags))
+		for i, d := range diags {
+			out[i] = lintJSON{d.Path, d.Line, d.Column, d.Severity.String(), d.Check, d.Msg}
+		}
+		b, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("json marshal: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case "github":
+		for _, d := range diags {
+			if _, err := fmt.Fprintln(w, githubAnnotation(d)); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, d := range diags {
+			if _, err := fmt.Fprintln(w, d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// githubAnnotation formats d as a GitHub Actions workflow command.
+func githubAnnotation(d pgini.Diagnostic) string {
+	level := "notice"
+	switch d.Severity {
+	case pgini.SeverityWarning:
+		level = "warning"
+	case pgini.SeverityError:
+		level = "error"
+	}
+	return fmt.Sprintf("::%s file=%s,line=%d,col=%d,title=%s::%s",
+		level, githubEscapeProperty(d.Path), d.Line, d.Column, d.Check, githubEscapeData(d.Msg))
+}
+
+// githubEscapeData escapes a workflow command message.
+func githubEscapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubEscapeProperty escapes a workflow command property value.
+func githubEscapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// displayPath returns p relative to the working directory when p is inside
+// it, and p unchanged otherwise.
+func displayPath(p string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return p
+	}
+	rel, err := filepath.Rel(wd, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return p
+	}
+	return rel
+}
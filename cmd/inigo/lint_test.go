@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thesmart/inigo/pgini"
+)
+
+var testDiag = pgini.Diagnostic{
+	Location: pgini.Location{Path: "conf/app.conf", Line: 3, Column: 7},
+	Severity: pgini.SeverityWarning,
+	Check:    pgini.CheckNeedsQuoting,
+	Msg:      "value \"abc\" is cut off by comment \";def\"; quote the value to keep it",
+}
+
+// ---------------------------------------------------------------------------
+// writeDiagnostics
+// ---------------------------------------------------------------------------
+
+func TestWriteDiagnostics_Text(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeDiagnostics(&b, "text", []pgini.Diagnostic{testDiag}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := testDiag.String() + "\n"
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
+
+func TestWriteDiagnostics_JSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeDiagnostics(&b, "json", []pgini.Diagnostic{testDiag}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, b.String())
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(got))
+	}
+	want := map[string]any{
+		"path":     "conf/app.conf",
+		"line":     float64(3),
+		"column":   float64(7),
+		"severity": "warning",
+		"check":    "needs-quoting",
+		"message":  testDiag.Msg,
+	}
+	for k, v := range want {
+		if got[0][k] != v {
+			t.Errorf("%s = %v, want %v", k, got[0][k], v)
+		}
+	}
+}
+
+func TestWriteDiagnostics_JSONEmpty(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeDiagnostics(&b, "json", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.String() != "[]\n" {
+		t.Errorf("got %q, want %q", b.String(), "[]\n")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// githubAnnotation
+// ---------------------------------------------------------------------------
+
+func TestGithubAnnotation(t *testing.T) {
+	d := testDiag
+	d.Path = "a,b:c.conf"
+	d.Msg = "100% wrong\nsecond line"
+	want := "::warning file=a%2Cb%3Ac.conf,line=3,col=7,title=needs-quoting::100%25 wrong%0Asecond line"
+	if got := githubAnnotation(d); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	d.Severity = pgini.SeverityInfo
+	if got := githubAnnotation(d); got[:9] != "::notice " {
+		t.Errorf("info should map to notice, got %q", got)
+	}
+	d.Severity = pgini.SeverityError
+	if got := githubAnnotation(d); got[:8] != "::error " {
+		t.Errorf("error should map to error, got %q", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// displayPath
+// ---------------------------------------------------------------------------
+
+func TestDisplayPath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := displayPath(filepath.Join(wd, "conf", "app.conf")); got != filepath.Join("conf", "app.conf") {
+		t.Errorf("inside working dir: got %q", got)
+	}
+	outside := filepath.Join(filepath.Dir(wd), "elsewhere.conf")
+	if got := displayPath(outside); got != outside {
+		t.Errorf("outside working dir: got %q, want %q", got, outside)
+	}
+}
@@ -20,6 +20,12 @@ own apps — without needing parsing in those apps.`,
   # Dump config as JSON for use in a shell script
   inigo json config.ini mydb | jq .
 
+  # Read a single value in a shell script
+  PORT=$(inigo get pg_service.conf mydb port)
+
+  # Edit a config in place, keeping its comments
+  inigo set pg_service.conf mydb port=6543
+
   # Use in a shell script
   #!/bin/sh
   exec inigo env /etc/myapp.conf -- ./myapp`,
@@ -31,6 +37,11 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&silent, "silent", "s", false, "suppress error messages on stderr")
 	rootCmd.AddCommand(envCmd)
 	rootCmd.AddCommand(jsonCmd)
+	rootCmd.AddCommand(fmtCmd)
+	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(setCmd)
+	rootCmd.AddCommand(unsetCmd)
 }
 
 func main() {
@@ -365,3 +365,315 @@ func TestJsonCaseExampleUppercase(t *testing.T) {
 		t.Errorf("expected HOST in JSON, got: %s", out)
 	}
 }
+
+func readIni(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestSetPreservesLayout(t *testing.T) {
+	ini := writeIni(t, "# app settings\nname = old # the name\n\n[mydb]\nhost = localhost\n")
+	out, err := exec.Command(testBinary, "set", ini, "name=new").CombinedOutput()
+	if err != nil {
+		t.Fatalf("set failed: %v\n%s", err, out)
+	}
+	want := "# app settings\nname = new # the name\n\n[mydb]\nhost = localhost\n"
+	if got := readIni(t, ini); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSetQuotesValues(t *testing.T) {
+	ini := writeIni(t, "[mydb]\nhost = localhost\n")
+	out, err := exec.Command(testBinary, "set", ini, "mydb", "password=it's a secret", "port=6543").CombinedOutput()
+	if err != nil {
+		t.Fatalf("set failed: %v\n%s", err, out)
+	}
+	want := "[mydb]\nhost = localhost\npassword = 'it\\'s a secret'\nport = 6543\n"
+	if got := readIni(t, ini); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSetCreatesSection(t *testing.T) {
+	ini := writeIni(t, "name = app\n")
+	out, err := exec.Command(testBinary, "set", ini, "mydb", "host=db.internal").CombinedOutput()
+	if err != nil {
+		t.Fatalf("set failed: %v\n%s", err, out)
+	}
+	want := "name = app\n\n[mydb]\nhost = db.internal\n"
+	if got := readIni(t, ini); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSetInvalidKey(t *testing.T) {
+	content := "name = app\n"
+	ini := writeIni(t, content)
+	if err := exec.Command(testBinary, "set", ini, "bad key=1").Run(); err == nil {
+		t.Fatal("expected error for invalid key")
+	}
+	if got := readIni(t, ini); got != content {
+		t.Errorf("file changed on error:\n%s", got)
+	}
+}
+
+func TestSetMissingFile(t *testing.T) {
+	err := exec.Command(testBinary, "set", "/nonexistent/file.ini", "a=1").Run()
+	if err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestUnsetPreservesLayout(t *testing.T) {
+	ini := writeIni(t, "debug = on\nname = app # keep me\n\n[mydb]\nhost = localhost\nport = 5432\n")
+	out, err := exec.Command(testBinary, "unset", ini, "debug").CombinedOutput()
+	if err != nil {
+		t.Fatalf("unset failed: %v\n%s", err, out)
+	}
+	out, err = exec.Command(testBinary, "unset", ini, "mydb", "port", "missing").CombinedOutput()
+	if err != nil {
+		t.Fatalf("unset failed: %v\n%s", err, out)
+	}
+	want := "name = app # keep me\n\n[mydb]\nhost = localhost\n"
+	if got := readIni(t, ini); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnsetMissingSection(t *testing.T) {
+	ini := writeIni(t, "name = app\n")
+	if err := exec.Command(testBinary, "unset", ini, "nosection", "host").Run(); err == nil {
+		t.Fatal("expected error for missing section")
+	}
+}
+
+func TestGetValue(t *testing.T) {
+	ini := writeIni(t, "name = 'it\\'s mine'\n\n[mydb]\nport = 5432\n")
+	out, err := exec.Command(testBinary, "get", ini, "name").Output()
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if string(out) != "it's mine\n" {
+		t.Errorf("got %q, want %q", out, "it's mine\n")
+	}
+
+	out, err = exec.Command(testBinary, "get", ini, "MyDB", "PORT").Output()
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if string(out) != "5432\n" {
+		t.Errorf("got %q, want %q", out, "5432\n")
+	}
+}
+
+func TestGetType(t *testing.T) {
+	ini := writeIni(t, "debug = on\nport = 0x1F90\nratio = .5\n")
+	for _, tt := range []struct{ typ, key, want string }{
+		{"bool", "debug", "true\n"},
+		{"int", "port", "8080\n"},
+		{"float", "ratio", "0.5\n"},
+	} {
+		out, err := exec.Command(testBinary, "get", "--type", tt.typ, ini, tt.key).Output()
+		if err != nil {
+			t.Fatalf("get --type %s failed: %v", tt.typ, err)
+		}
+		if string(out) != tt.want {
+			t.Errorf("get --type %s = %q, want %q", tt.typ, out, tt.want)
+		}
+	}
+}
+
+func TestGetTypeInvalidValue(t *testing.T) {
+	ini := writeIni(t, "debug = sometimes\n")
+	err := exec.Command(testBinary, "get", "--type", "bool", ini, "debug").Run()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("expected ExitError, got %v", err)
+	}
+	if exitErr.ExitCode() != 1 {
+		t.Errorf("exit code = %d, want 1", exitErr.ExitCode())
+	}
+}
+
+func TestGetMissing(t *testing.T) {
+	ini := writeIni(t, "[mydb]\nhost = localhost\n")
+	for _, args := range [][]string{
+		{"get", ini, "host"},
+		{"get", ini, "mydb", "port"},
+		{"get", ini, "nosection", "host"},
+	} {
+		err := exec.Command(testBinary, args...).Run()
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			t.Fatalf("%v: expected ExitError, got %v", args, err)
+		}
+		if exitErr.ExitCode() != 2 {
+			t.Errorf("%v: exit code = %d, want 2", args, exitErr.ExitCode())
+		}
+	}
+}
+
+func TestGetDefault(t *testing.T) {
+	ini := writeIni(t, "[mydb]\nhost = localhost\n")
+	out, err := exec.Command(testBinary, "get", "--default", "5432", ini, "mydb", "port").Output()
+	if err != nil {
+		t.Fatalf("get --default failed: %v", err)
+	}
+	if string(out) != "5432\n" {
+		t.Errorf("got %q, want %q", out, "5432\n")
+	}
+
+	out, err = exec.Command(testBinary, "get", "--default=", ini, "nosection", "port").Output()
+	if err != nil {
+		t.Fatalf("get --default= failed: %v", err)
+	}
+	if string(out) != "\n" {
+		t.Errorf("got %q, want %q", out, "\n")
+	}
+}
+
+func TestGetMissingFile(t *testing.T) {
+	err := exec.Command(testBinary, "get", "/nonexistent/file.ini", "host").Run()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("expected ExitError, got %v", err)
+	}
+	if exitErr.ExitCode() != 1 {
+		t.Errorf("exit code = %d, want 1", exitErr.ExitCode())
+	}
+}
+
+func TestFmtRewritesFile(t *testing.T) {
+	ini := writeIni(t, "; app\nhost:localhost\nport = '5432'\n[MyDB]\n")
+	out, err := exec.Command(testBinary, "fmt", ini).CombinedOutput()
+	if err != nil {
+		t.Fatalf("fmt failed: %v\n%s", err, out)
+	}
+	want := "# app\nhost = localhost\nport = 5432\n[mydb]\n"
+	if got := readIni(t, ini); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFmtCheck(t *testing.T) {
+	formatted := writeIni(t, "a = 1\n")
+	if out, err := exec.Command(testBinary, "fmt", "--check", formatted).CombinedOutput(); err != nil {
+		t.Fatalf("fmt --check on formatted file failed: %v\n%s", err, out)
+	}
+
+	content := "a=1\n"
+	unformatted := writeIni(t, content)
+	out, err := exec.Command(testBinary, "fmt", "--check", formatted, unformatted).Output()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("expected ExitError, got %v", err)
+	}
+	if exitErr.ExitCode() != 1 {
+		t.Errorf("exit code = %d, want 1", exitErr.ExitCode())
+	}
+	if string(out) != unformatted+"\n" {
+		t.Errorf("got %q, want %q", out, unformatted+"\n")
+	}
+	if got := readIni(t, unformatted); got != content {
+		t.Errorf("--check wrote the file:\n%s", got)
+	}
+}
+
+func TestFmtDiff(t *testing.T) {
+	content := "a=1\nb = 2\n"
+	ini := writeIni(t, content)
+	out, err := exec.Command(testBinary, "fmt", "--diff", ini).Output()
+	if err != nil {
+		t.Fatalf("fmt --diff failed: %v", err)
+	}
+	if !strings.Contains(string(out), "-a=1\n+a = 1\n b = 2\n") {
+		t.Errorf("unexpected diff:\n%s", out)
+	}
+	if got := readIni(t, ini); got != content {
+		t.Errorf("--diff wrote the file:\n%s", got)
+	}
+}
+
+func TestFmtParseError(t *testing.T) {
+	content := "bad = 'open\n"
+	ini := writeIni(t, content)
+	out, err := exec.Command(testBinary, "fmt", ini).CombinedOutput()
+	if err == nil {
+		t.Fatal("expected error for unparsable file")
+	}
+	if !strings.Contains(string(out), ini+":1:") {
+		t.Errorf("expected error position in output, got:\n%s", out)
+	}
+	if got := readIni(t, ini); got != content {
+		t.Errorf("file changed on error:\n%s", got)
+	}
+}
+
+func lintExitCode(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	out, err := exec.Command(testBinary, append([]string{"lint"}, args...)...).Output()
+	if err == nil {
+		return 0, string(out)
+	}
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("expected ExitError, got %v", err)
+	}
+	return exitErr.ExitCode(), string(out)
+}
+
+func TestLintClean(t *testing.T) {
+	ini := writeIni(t, "a = 1\n\n[unused]\n")
+	code, out := lintExitCode(t, ini)
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0 for info only", code)
+	}
+	if !strings.Contains(out, "info: section [unused] has no parameters (empty-section)") {
+		t.Errorf("expected empty-section info, got:\n%s", out)
+	}
+}
+
+func TestLintWarning(t *testing.T) {
+	ini := writeIni(t, "a = 1\na = 2\n")
+	code, out := lintExitCode(t, ini)
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(out, ":1:1: warning: key \"a\" is overridden at line 2 (shadowed-key)") {
+		t.Errorf("expected shadowed-key warning, got:\n%s", out)
+	}
+}
+
+func TestLintError(t *testing.T) {
+	ini := writeIni(t, "a = 1\na = 2\nb = 'open\n")
+	code, out := lintExitCode(t, "--format", "github", ini)
+	if code != 2 {
+		t.Errorf("exit code = %d, want 2", code)
+	}
+	if !strings.Contains(out, "::warning ") || !strings.Contains(out, "::error ") {
+		t.Errorf("expected warning and error annotations, got:\n%s", out)
+	}
+}
+
+func TestLintValidateAlias(t *testing.T) {
+	ini := writeIni(t, "a = 1\n")
+	out, err := exec.Command(testBinary, "validate", "--format", "json", ini).Output()
+	if err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	if string(out) != "[]\n" {
+		t.Errorf("got %q, want %q", out, "[]\n")
+	}
+}
+
+func TestLintBadFormat(t *testing.T) {
+	ini := writeIni(t, "a = 1\n")
+	if code, _ := lintExitCode(t, "--format", "xml", ini); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+}
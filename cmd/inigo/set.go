@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thesmart/inigo/pgini"
+)
+
+var setCmd = &cobra.Command{
+	Use:   "set <ini-file> [section] <key=value>...",
+	Short: "Set INI params in place, preserving comments and layout",
+	Long: `Set one or more parameters in an INI file, editing it in place.
+
+If the first argument after the file has no '=', it names the section;
+otherwise the default (unnamed) section is used. Missing sections and keys
+are created. Values are quoted and escaped as needed. Every other line of
+the file, including comments, is left untouched. The file is replaced
+atomically, so readers never see a partial write.`,
+	Example: `  # Set a key in the default section
+  inigo set app.conf name=myapp
+
+  # Set several keys in a named section (created if missing)
+  inigo set pg_service.conf mydb host=db.internal port=6543
+
+  # Values with spaces or quotes are quoted for you
+  inigo set app.conf greeting="it's a nice day"`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runSet,
+}
+
+func runSet(cmd *cobra.Command, args []string) error {
+	iniFile, section, assignments, err := splitSetArgs(args)
+	if err != nil {
+		return err
+	}
+
+	doc, err := pgini.ParseDocument(iniFile)
+	if err != nil {
+		return err
+	}
+
+	sec, err := doc.File.AddSection(section)
+	if err != nil {
+		return err
+	}
+	for _, a := range assignments {
+		if _, err := sec.SetParam(a[0], a[1]); err != nil {
+			return err
+		}
+	}
+
+	return writeDocument(iniFile, doc)
+}
+
+// splitSetArgs splits set arguments into the ini-file, optional section, and
+// key=value assignments. The section is present when the second argument
+// contains no '='.
+func splitSetArgs(args []string) (iniFile, section string, assignments [][2]string, err error) {
+	if len(args) < 2 {
+		return "", "", nil, fmt.Errorf("expected <ini-file> [section] <key=value>..., got %d argument(s)", len(args))
+	}
+
+	iniFile = args[0]
+	rest := args[1:]
+	if !strings.Contains(rest[0], "=") {
+		section = rest[0]
+		rest = rest[1:]
+	}
+	if len(rest) == 0 {
+		return "", "", nil, fmt.Errorf("missing <key=value> after section %q", section)
+	}
+
+	for _, arg := range rest {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return "", "", nil, fmt.Errorf("expected key=value, got %q", arg)
+		}
+		assignments = append(assignments, [2]string{key, value})
+	}
+	return iniFile, section, assignments, nil
+}
+
+// writeDocument marshals doc and atomically replaces the file at filePath.
+func writeDocument(filePath string, doc *pgini.Document) error {
+	contents, err := doc.MarshalIni()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filePath, contents)
+}
+
+// writeFileAtomic writes contents to a temporary file next to filePath and
+// renames it over filePath, keeping the original file's permissions.
+func writeFileAtomic(filePath string, contents []byte) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	if _, err := tmp.Write(contents); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, filePath)
+}
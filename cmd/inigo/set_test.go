@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ---------------------------------------------------------------------------
+// splitSetArgs
+// ---------------------------------------------------------------------------
+
+func TestSplitSetArgs_DefaultSection(t *testing.T) {
+	iniFile, section, assignments, err := splitSetArgs([]string{"app.conf", "a=1", "b="})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if iniFile != "app.conf" {
+		t.Errorf("iniFile = %q, want %q", iniFile, "app.conf")
+	}
+	if section != "" {
+		t.Errorf("section = %q, want empty", section)
+	}
+	want := [][2]string{{"a", "1"}, {"b", ""}}
+	if len(assignments) != len(want) {
+		t.Fatalf("assignments = %v, want %v", assignments, want)
+	}
+	for i := range want {
+		if assignments[i] != want[i] {
+			t.Errorf("assignments[%d] = %v, want %v", i, assignments[i], want[i])
+		}
+	}
+}
+
+func TestSplitSetArgs_NamedSection(t *testing.T) {
+	_, section, assignments, err := splitSetArgs([]string{"app.conf", "mydb", "url=a=b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if section != "mydb" {
+		t.Errorf("section = %q, want %q", section, "mydb")
+	}
+	if len(assignments) != 1 || assignments[0] != [2]string{"url", "a=b"} {
+		t.Errorf("assignments = %v, want [[url a=b]]", assignments)
+	}
+}
+
+func TestSplitSetArgs_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"no args", nil},
+		{"file only", []string{"app.conf"}},
+		{"section only", []string{"app.conf", "mydb"}},
+		{"missing equals", []string{"app.conf", "mydb", "key"}},
+		{"empty key", []string{"app.conf", "=value"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := splitSetArgs(tt.args); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// splitUnsetArgs
+// ---------------------------------------------------------------------------
+
+func TestSplitUnsetArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantSection string
+		wantKeys    []string
+	}{
+		{"default key", []string{"app.conf", "debug"}, "", []string{"debug"}},
+		{"named section", []string{"app.conf", "mydb", "host"}, "mydb", []string{"host"}},
+		{"several keys", []string{"app.conf", "default", "a", "b"}, "default", []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iniFile, section, keys, err := splitUnsetArgs(tt.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if iniFile != "app.conf" {
+				t.Errorf("iniFile = %q, want %q", iniFile, "app.conf")
+			}
+			if section != tt.wantSection {
+				t.Errorf("section = %q, want %q", section, tt.wantSection)
+			}
+			if len(keys) != len(tt.wantKeys) {
+				t.Fatalf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+			for i := range keys {
+				if keys[i] != tt.wantKeys[i] {
+					t.Errorf("keys[%d] = %q, want %q", i, keys[i], tt.wantKeys[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitUnsetArgs_FileOnly(t *testing.T) {
+	if _, _, _, err := splitUnsetArgs([]string{"app.conf"}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// writeFileAtomic
+// ---------------------------------------------------------------------------
+
+func TestWriteFileAtomic_KeepsMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.conf")
+	if err := os.WriteFile(path, []byte("a = 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(path, []byte("a = 2\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "a = 2\n" {
+		t.Errorf("contents = %q, want %q", got, "a = 2\n")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), os.FileMode(0o600))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected temp file to be gone, dir has %d entries", len(entries))
+	}
+}
+
+func TestWriteFileAtomic_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.conf")
+	if err := writeFileAtomic(path, []byte("a = 1\n")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thesmart/inigo/pgini"
+)
+
+var unsetCmd = &cobra.Command{
+	Use:   "unset <ini-file> [section] <key>...",
+	Short: "Remove INI params in place, preserving comments and layout",
+	Long: `Remove one or more parameters from an INI file, editing it in place.
+
+With a single key, the default (unnamed) section is used. With two or more
+arguments after the file, the first names the section; use "default" to
+remove several keys from the default section. Every definition of each key
+is removed; keys that are not set are ignored. Every other line of the file,
+including comments, is left untouched. The file is replaced atomically.`,
+	Example: `  # Remove a key from the default section
+  inigo unset app.conf debug
+
+  # Remove keys from a named section
+  inigo unset pg_service.conf mydb password sslmode
+
+  # Remove several keys from the default section
+  inigo unset app.conf default debug verbose`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runUnset,
+}
+
+func runUnset(cmd *cobra.Command, args []string) error {
+	iniFile, section, keys, err := splitUnsetArgs(args)
+	if err != nil {
+		return err
+	}
+
+	doc, err := pgini.ParseDocument(iniFile)
+	if err != nil {
+		return err
+	}
+
+	sec := doc.File.GetSection(section)
+	if sec == nil {
+		return fmt.Errorf("section %q not found in %s", section, iniFile)
+	}
+	for _, key := range keys {
+		sec.RemoveParam(key)
+	}
+
+	return writeDocument(iniFile, doc)
+}
+
+// splitUnsetArgs splits unset arguments into the ini-file, optional section,
+// and keys. The section is present when more than one argument follows the
+// ini-file.
+func splitUnsetArgs(args []string) (iniFile, section string, keys []string, err error) {
+	switch len(args) {
+	case 0, 1:
+		return "", "", nil, fmt.Errorf("expected <ini-file> [section] <key>..., got %d argument(s)", len(args))
+	case 2:
+		return args[0], "", args[1:], nil
+	default:
+		return args[0], args[1], args[2:], nil
+	}
+}
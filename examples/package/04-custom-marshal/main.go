@@ -1,14 +1,18 @@
 // Example 04: Custom marshal and unmarshal methods.
 //
-// For types that aren't primitives (like time.Duration or []string),
-// define Marshal<FieldName> and Unmarshal<FieldName> methods on the struct.
-// pgini calls these automatically during MarshalSection and UnmarshalSection.
+// For types that pgini does not support (like map[string]string), define
+// Marshal<FieldName> and Unmarshal<FieldName> methods on the struct. pgini
+// calls these automatically during MarshalSection and UnmarshalSection.
+// time.Duration and slices need no methods: pgini decodes "30s" and
+// 'api, production, v2' on its own.
 
 package main
 
 import (
 	"fmt"
 	"log"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,33 +20,31 @@ import (
 )
 
 type Config struct {
-	Timeout time.Duration `ini:"timeout"`
-	Tags    []string      `ini:"tags"`
+	Timeout time.Duration     `ini:"timeout"`
+	Tags    []string          `ini:"tags"`
+	Labels  map[string]string `ini:"labels"`
 }
 
-// UnmarshalTimeout parses a Go duration string (e.g. "30s") into time.Duration.
-func (c *Config) UnmarshalTimeout(value string) (*time.Duration, error) {
-	d, err := time.ParseDuration(value)
-	if err != nil {
-		return nil, err
+// UnmarshalLabels parses comma-separated key=value pairs into a map.
+func (c *Config) UnmarshalLabels(value string) (*map[string]string, error) {
+	labels := make(map[string]string)
+	for pair := range strings.SplitSeq(value, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("label %q: missing '='", pair)
+		}
+		labels[strings.TrimSpace(k)] = strings.TrimSpace(v)
 	}
-	return &d, nil
-}
-
-// MarshalTimeout formats time.Duration back to a string.
-func (c *Config) MarshalTimeout(value *time.Duration) (string, error) {
-	return value.String(), nil
+	return &labels, nil
 }
 
-// UnmarshalTags splits a comma-separated string into a slice.
-func (c *Config) UnmarshalTags(value string) (*[]string, error) {
-	parts := strings.Split(value, ",")
-	return &parts, nil
-}
-
-// MarshalTags joins a slice back into a comma-separated string.
-func (c *Config) MarshalTags(value *[]string) (string, error) {
-	return strings.Join(*value, ","), nil
+// MarshalLabels joins a map back into comma-separated key=value pairs.
+func (c *Config) MarshalLabels(value *map[string]string) (string, error) {
+	var pairs []string
+	for _, k := range slices.Sorted(maps.Keys(*value)) {
+		pairs = append(pairs, k+"="+(*value)[k])
+	}
+	return strings.Join(pairs, ","), nil
 }
 
 func main() {
@@ -55,6 +57,7 @@ func main() {
 	fmt.Println("=== Loaded ===")
 	fmt.Printf("Timeout: %s\n", cfg.Timeout)
 	fmt.Printf("Tags:    %v\n", cfg.Tags)
+	fmt.Printf("Labels:  %v\n", cfg.Labels)
 
 	// Round-trip: marshal back to a new IniFile.
 	f, err := pgini.NewIniFile("roundtrip.conf")
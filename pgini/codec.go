@@ -0,0 +1,96 @@
+// Struct plans are compiled once per struct type and cached: the mapped
+// fields, with their tags parsed, embedded and inline structs flattened, and
+// their custom Unmarshal<FieldName> and Marshal<FieldName> methods resolved
+// and their signatures checked. Decoding or encoding a type again reuses its
+// plan instead of walking its fields, parsing tags, and looking up methods by
+// name. Plans are safe for concurrent use, and never change once compiled.
+
+package pgini
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// structPlan is the compiled plan of a struct type, or the error that
+// compiling it returned.
+type structPlan struct {
+	fields []taggedField
+	err    error
+}
+
+// plans caches the *structPlan of each struct type, keyed by reflect.Type.
+var plans sync.Map
+
+// taggedFields returns the mapped fields of structType, as compiled by
+// compileFields, compiling them on first use. The returned slice is shared
+// and must not be modified.
+func taggedFields(structType reflect.Type) ([]taggedField, error) {
+	if plan, ok := plans.Load(structType); ok {
+		return plan.(*structPlan).fields, plan.(*structPlan).err
+	}
+	fields, err := compileFields(structType)
+	plan, _ := plans.LoadOrStore(structType, &structPlan{fields: fields, err: err})
+	return plan.(*structPlan).fields, plan.(*structPlan).err
+}
+
+// customMethod is a custom Unmarshal<FieldName> or Marshal<FieldName> method.
+type customMethod struct {
+	// index is the method's index in the method set of a pointer to the
+	// struct that declares, or promotes, it.
+	index int
+	// err describes a wrong signature. It is returned when the method would
+	// be called, so that a bad method fails only the field it applies to.
+	err error
+}
+
+// resolveMethods sets the custom methods of field, a field of structType, by
+// looking them up on the struct that field.receiver leads to. It returns an
+// error when a method that the struct declaring the field has is not promoted
+// to that struct, as when two embedded structs declare the same method name,
+// since the method would otherwise be skipped without notice.
+func resolveMethods(structType reflect.Type, field *taggedField) error {
+	receiverType, declType := structType, structType
+	for depth, i := range field.index[:len(field.index)-1] {
+		declType = declType.Field(i).Type
+		if declType.Kind() == reflect.Pointer {
+			declType = declType.Elem()
+		}
+		if depth+1 == field.receiver {
+			receiverType = declType
+		}
+	}
+	ptrType := reflect.PointerTo(receiverType)
+
+	for _, name := range []string{"Unmarshal" + field.def.Name, "Marshal" + field.def.Name} {
+		m, ok := ptrType.MethodByName(name)
+		if !ok {
+			if _, declared := reflect.PointerTo(declType).MethodByName(name); declared {
+				return fmt.Errorf("field %s: method %s of %s is ambiguous in %s; declare %s on %s", field.name, name, declType, receiverType, name, receiverType)
+			}
+			continue
+		}
+		if name == "Unmarshal"+field.def.Name {
+			field.unmarshalMethod = &customMethod{index: m.Index, err: checkUnmarshalMethod(methodSig(m), field.def)}
+		} else {
+			field.marshalMethod = &customMethod{index: m.Index, err: checkMarshalMethod(methodSig(m), field.def)}
+		}
+	}
+	return nil
+}
+
+// methodSig returns the signature of m without its receiver, as the type of
+// the method's bound value would be.
+func methodSig(m reflect.Method) reflect.Type {
+	t := m.Type
+	in := make([]reflect.Type, t.NumIn()-1)
+	for i := range in {
+		in[i] = t.In(i + 1)
+	}
+	out := make([]reflect.Type, t.NumOut())
+	for i := range out {
+		out[i] = t.Out(i)
+	}
+	return reflect.FuncOf(in, out, t.IsVariadic())
+}
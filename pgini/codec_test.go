@@ -0,0 +1,239 @@
+package pgini
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type codecDatabase struct {
+	Host     string        `ini:"host" default:"localhost"`
+	Port     int           `ini:"port" validate:"port"`
+	User     string        `ini:"user,required"`
+	Timeout  time.Duration `ini:"timeout,unit=s"`
+	WorkMem  int64         `ini:"work_mem,unit=kB"`
+	Hosts    []string      `ini:"hosts"`
+	ReadOnly bool          `ini:"read_only"`
+	Ratio    float64       `ini:"ratio"`
+	Retries  *int          `ini:"retries"`
+	Version  string        `ini:"version"`
+}
+
+// UnmarshalVersion is a custom method, resolved once per type.
+func (d *codecDatabase) UnmarshalVersion(value string) (*string, error) {
+	v := strings.TrimPrefix(value, "v")
+	return &v, nil
+}
+
+// MarshalVersion is a custom method, resolved once per type.
+func (d *codecDatabase) MarshalVersion(value *string) (string, error) {
+	return "v" + *value, nil
+}
+
+const codecConf = `[database]
+host = db.example.com
+port = 5432
+user = app
+timeout = 30s
+work_mem = 4MB
+hosts = 'a.example.com, b.example.com'
+read_only = on
+ratio = 0.25
+retries = 3
+version = v1.2
+`
+
+// --- taggedFields cache tests ---
+
+func TestTaggedFields_Cached(t *testing.T) {
+	typ := reflect.TypeFor[codecDatabase]()
+	first, err := taggedFields(typ)
+	if err != nil {
+		t.Fatalf("taggedFields: %v", err)
+	}
+	second, err := taggedFields(typ)
+	if err != nil {
+		t.Fatalf("taggedFields: %v", err)
+	}
+	if &first[0] != &second[0] {
+		t.Error("taggedFields should return the cached plan on the second call")
+	}
+	if _, ok := plans.Load(typ); !ok {
+		t.Error("plan should be stored in the cache")
+	}
+
+	version := first[len(first)-1]
+	if version.unmarshalMethod == nil || version.marshalMethod == nil {
+		t.Errorf("Version methods = %v, %v; want both resolved", version.unmarshalMethod, version.marshalMethod)
+	}
+}
+
+func TestTaggedFields_CachesErrors(t *testing.T) {
+	type bad struct {
+		Port int `ini:"port,bogus"`
+	}
+	for range 2 {
+		if _, err := taggedFields(reflect.TypeFor[bad]()); err == nil || !strings.Contains(err.Error(), "field Port") {
+			t.Errorf("expected error naming field Port, got %v", err)
+		}
+	}
+}
+
+func TestTaggedFields_MethodSignatureCheckedOnce(t *testing.T) {
+	fields, err := taggedFields(reflect.TypeFor[badUnmarshalWrongParamType]())
+	if err != nil {
+		t.Fatalf("taggedFields: %v", err)
+	}
+	m := fields[0].unmarshalMethod
+	if m == nil || m.err == nil || !strings.Contains(m.err.Error(), "parameter must be string") {
+		t.Errorf("unmarshalMethod = %+v, want a signature error", m)
+	}
+}
+
+// codecPrimary and codecReplica both declare UnmarshalHost, so a struct that
+// embeds both has no promoted UnmarshalHost.
+type codecPrimary struct {
+	Host string `ini:"primary_host"`
+}
+
+func (p *codecPrimary) UnmarshalHost(value string) (*string, error) { return &value, nil }
+
+type codecReplica struct {
+	Host string `ini:"replica_host"`
+}
+
+func (r *codecReplica) UnmarshalHost(value string) (*string, error) { return &value, nil }
+
+func TestTaggedFields_AmbiguousMethod(t *testing.T) {
+	type cluster struct {
+		codecPrimary
+		codecReplica
+	}
+	_, err := taggedFields(reflect.TypeFor[cluster]())
+	want := "field Host: method UnmarshalHost of pgini.codecPrimary is ambiguous in pgini.cluster"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("expected error containing %q, got %v", want, err)
+	}
+
+	// Declaring the method on the outer struct resolves the ambiguity.
+	fields, err := taggedFields(reflect.TypeFor[codecCluster]())
+	if err != nil {
+		t.Fatalf("taggedFields: %v", err)
+	}
+	for _, field := range fields {
+		if field.unmarshalMethod == nil {
+			t.Errorf("field %s: UnmarshalHost not resolved", field.name)
+		}
+	}
+}
+
+type codecCluster struct {
+	codecPrimary
+	codecReplica
+}
+
+func (c *codecCluster) UnmarshalHost(value string) (*string, error) { return &value, nil }
+
+func TestUnmarshalSection_Concurrent(t *testing.T) {
+	f, err := ParseString("codec.conf", codecConf)
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	plans.Delete(reflect.TypeFor[codecDatabase]())
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	got := make([]codecDatabase, 8)
+	for i := range 8 {
+		wg.Go(func() {
+			errs[i] = f.UnmarshalSection("database", &got[i])
+		})
+	}
+	wg.Wait()
+	for i := range 8 {
+		if errs[i] != nil {
+			t.Fatalf("goroutine %d: %v", i, errs[i])
+		}
+		if got[i].Version != "1.2" || got[i].WorkMem != 4096 {
+			t.Errorf("goroutine %d: got %+v", i, got[i])
+		}
+	}
+}
+
+// --- benchmarks ---
+
+// benchmarkCodec runs decode b.N times, clearing the plan cache before each
+// run when uncached, as every call behaved before plans were cached.
+func benchmarkCodec(b *testing.B, uncached bool, decode func() error) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if uncached {
+			plans.Clear()
+		}
+		if err := decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalSection(b *testing.B) {
+	f, err := ParseString("codec.conf", codecConf)
+	if err != nil {
+		b.Fatalf("ParseString: %v", err)
+	}
+	for _, uncached := range []bool{false, true} {
+		name := "cached"
+		if uncached {
+			name = "uncached"
+		}
+		b.Run(name, func(b *testing.B) {
+			benchmarkCodec(b, uncached, func() error {
+				return f.UnmarshalSection("database", &codecDatabase{})
+			})
+		})
+	}
+}
+
+func BenchmarkMarshalSection(b *testing.B) {
+	f, err := ParseString("codec.conf", codecConf)
+	if err != nil {
+		b.Fatalf("ParseString: %v", err)
+	}
+	v := &codecDatabase{}
+	if err := f.UnmarshalSection("database", v); err != nil {
+		b.Fatalf("UnmarshalSection: %v", err)
+	}
+	for _, uncached := range []bool{false, true} {
+		name := "cached"
+		if uncached {
+			name = "uncached"
+		}
+		b.Run(name, func(b *testing.B) {
+			benchmarkCodec(b, uncached, func() error {
+				out, err := NewIniFile("out.conf")
+				if err != nil {
+					return err
+				}
+				return out.MarshalSection("database", v)
+			})
+		})
+	}
+}
+
+func BenchmarkUnmarshalSections(b *testing.B) {
+	var conf strings.Builder
+	for i := range 200 {
+		conf.WriteString(strings.Replace(codecConf, "[database]", fmt.Sprintf("[db_%03d]", i), 1))
+	}
+	f, err := ParseString("codec.conf", conf.String())
+	if err != nil {
+		b.Fatalf("ParseString: %v", err)
+	}
+	benchmarkCodec(b, false, func() error {
+		_, err := UnmarshalSections[codecDatabase](f, "db_*")
+		return err
+	})
+}
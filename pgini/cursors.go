@@ -11,7 +11,9 @@ package pgini
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"unicode/utf8"
@@ -29,6 +31,12 @@ type RootCursor struct {
 	stack []*FileCursor
 	// Tracks how many times each file has been visited
 	visited map[string]int
+	// Filesystem that includes are read from; nil reads from the OS
+	fsys fs.FS
+	// Whether line errors are collected in errs instead of stopping the parse
+	recovering bool
+	// Line errors collected in recovery mode, in the order encountered
+	errs []*ParseError
 }
 
 // maxVisitCount is the maximum number of times a single file may be included
@@ -47,56 +55,187 @@ func NewRootCursor(filePath string) (*RootCursor, error) {
 		return nil, fmt.Errorf("failed to read %q: %w", absPath, err)
 	}
 
-	f, err := NewIniFile(absPath)
+	return newRootCursor(absPath, filepath.Dir(absPath), contents)
+}
+
+// NewRootCursorBytes returns a new RootCursor over in-memory contents. The name
+// identifies the source in errors and in IniFile.Path. Relative include paths
+// resolve against baseDir; when baseDir is empty, include directives are
+// rejected.
+func NewRootCursorBytes(name string, contents []byte, baseDir string) (*RootCursor, error) {
+	filePath := name
+	dir := ""
+	if baseDir != "" {
+		absDir, err := filepath.Abs(baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve path %q: %w", baseDir, err)
+		}
+		dir = absDir
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(absDir, filePath)
+		}
+	}
+
+	return newRootCursor(filePath, dir, contents)
+}
+
+// NewRootCursorFS reads the file at name within fsys and returns a new
+// RootCursor. Include directives resolve within fsys: relative paths against
+// the including file's directory, and absolute paths against the root of fsys.
+// Includes that would escape the root of fsys are rejected.
+func NewRootCursorFS(fsys fs.FS, name string) (*RootCursor, error) {
+	if fsys == nil {
+		return nil, errors.New("NewRootCursorFS: fsys is nil")
+	}
+	if !fs.ValidPath(name) {
+		return nil, fmt.Errorf("invalid path %q: must be a valid fs.FS path", name)
+	}
+
+	contents, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", name, err)
+	}
+
+	c, err := newRootCursor(name, path.Dir(name), contents)
+	if err != nil {
+		return nil, err
+	}
+	c.fsys = fsys
+	return c, nil
+}
+
+// newRootCursor constructs a RootCursor whose root file has the given path,
+// include directory, and contents. The root file is pushed onto the stack and
+// counted as visited.
+func newRootCursor(filePath string, dir string, contents []byte) (*RootCursor, error) {
+	f, err := NewIniFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to construct IniFile %q: %w", absPath, err)
+		return nil, fmt.Errorf("failed to construct IniFile %q: %w", filePath, err)
 	}
 
+	root := newFileCursor(filePath, dir, contents)
 	c := &RootCursor{
-		File: f,
-		current: &FileCursor{
-			Path:       absPath,
-			contents:   strings.Split(string(contents), "\n"),
-			lineOffset: 0,
-			byteOffset: 0,
-		},
-		stack:   make([]*FileCursor, 0),
-		visited: make(map[string]int),
-	}
-	c.AddInclude(absPath)
+		File:    f,
+		current: root,
+		stack:   []*FileCursor{root},
+		visited: map[string]int{filePath: 1},
+	}
 	return c, nil
 }
 
 // AddInclude pushes a new included file onto the traversal stack.
 // Relative paths are resolved against the directory of the current file.
-// It returns an error if the path creates a circular include.
+// It returns an error if the path creates a circular include, or if the
+// current file has no directory to resolve includes against.
 func (c *RootCursor) AddInclude(includePath string) error {
 	if c.current == nil {
 		return errors.New("IncludesCursor#Add: unable to push new include, current is nil")
 	}
 
-	// Resolve relative paths against the directory of the current file.
-	if !filepath.IsAbs(includePath) {
-		includePath = filepath.Join(filepath.Dir(c.current.Path), includePath)
-	}
-	absPath, err := filepath.Abs(includePath)
+	resolved, err := c.resolvePath(includePath)
 	if err != nil {
-		return fmt.Errorf("failed to resolve path %q: %w", includePath, err)
+		return err
 	}
-	if c.visited[absPath] >= maxVisitCount {
-		return fmt.Errorf("%s:%d:%d: %s", c.current.Path, c.current.lineOffset, c.current.byteOffset, "circular include detected")
+	return c.pushInclude(resolved)
+}
+
+// pushInclude reads the already-resolved file at filePath and pushes it onto
+// the traversal stack. It returns an error if the path creates a circular
+// include.
+func (c *RootCursor) pushInclude(filePath string) error {
+	if c.current == nil {
+		return errors.New("RootCursor#pushInclude: current is nil")
+	}
+	if c.visited[filePath] >= maxVisitCount {
+		source, _ := c.current.GetLine()
+		return &ParseError{
+			Path:   c.current.Path,
+			Line:   max(c.current.lineOffset, 0) + 1,
+			Column: max(c.current.byteOffset, 0) + 1,
+			Kind:   CircularInclude,
+			Source: source,
+			Msg:    "circular include detected",
+		}
 	}
 
-	next, err := NewFileCursor(absPath)
+	next, err := c.openFile(filePath)
 	if err != nil {
 		return err
 	}
 	c.stack = append(c.stack, next)
-	c.visited[absPath]++
+	c.visited[filePath]++
 
 	return nil
 }
 
+// resolvePath returns the resolved form of includePath. Relative paths are
+// resolved against the directory of the current file. On the OS filesystem the
+// result is absolute; within an fs.FS it is a valid fs.FS path.
+func (c *RootCursor) resolvePath(includePath string) (string, error) {
+	if c.current == nil {
+		return "", errors.New("RootCursor#resolvePath: current is nil")
+	}
+	if c.current.dir == "" {
+		return "", fmt.Errorf("cannot include %q: no base directory for %s", includePath, c.current.Path)
+	}
+
+	if c.fsys != nil {
+		resolved := includePath
+		if path.IsAbs(resolved) {
+			resolved = strings.TrimPrefix(path.Clean(resolved), "/")
+		} else {
+			resolved = path.Join(c.current.dir, resolved)
+		}
+		if resolved == "" {
+			resolved = "."
+		}
+		if !fs.ValidPath(resolved) {
+			return "", fmt.Errorf("cannot include %q: path escapes the filesystem root", includePath)
+		}
+		return resolved, nil
+	}
+
+	if !filepath.IsAbs(includePath) {
+		includePath = filepath.Join(c.current.dir, includePath)
+	}
+	absPath, err := filepath.Abs(includePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %q: %w", includePath, err)
+	}
+	return absPath, nil
+}
+
+// openFile reads the resolved file at filePath from the cursor's filesystem
+// and returns a new FileCursor over it.
+func (c *RootCursor) openFile(filePath string) (*FileCursor, error) {
+	if c.fsys == nil {
+		return NewFileCursor(filePath)
+	}
+
+	contents, err := fs.ReadFile(c.fsys, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", filePath, err)
+	}
+	return newFileCursor(filePath, path.Dir(filePath), contents), nil
+}
+
+// readDir lists the resolved directory at dirPath from the cursor's filesystem.
+func (c *RootCursor) readDir(dirPath string) ([]fs.DirEntry, error) {
+	if c.fsys == nil {
+		return os.ReadDir(dirPath)
+	}
+	return fs.ReadDir(c.fsys, dirPath)
+}
+
+// joinPath joins a resolved directory and an entry name using the separator
+// of the cursor's filesystem.
+func (c *RootCursor) joinPath(dirPath string, name string) string {
+	if c.fsys == nil {
+		return filepath.Join(dirPath, name)
+	}
+	return path.Join(dirPath, name)
+}
+
 // NextInclude pops the next included file from the stack and makes it current.
 // It returns false when the stack is empty.
 func (c *RootCursor) NextInclude() *FileCursor {
@@ -127,7 +266,9 @@ func (c *RootCursor) String() string {
 // line and character positions within a single file. Call `NextLine()`
 // and `NextChar()` on a new FileCursor before attempting to read.
 type FileCursor struct {
-	Path       string
+	Path string
+	// dir resolves relative include paths; empty means includes are rejected
+	dir        string
 	contents   []string
 	lineOffset int // 0-indexed
 	byteOffset int // 0-indexed
@@ -141,13 +282,23 @@ func NewFileCursor(path string) (*FileCursor, error) {
 		return nil, fmt.Errorf("failed to resolve path %q: %w", path, err)
 	}
 
-	c := &FileCursor{Path: absPath, lineOffset: -1, byteOffset: -1}
 	contents, err := os.ReadFile(absPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read %q: %w", absPath, err)
 	}
-	c.contents = strings.Split(string(contents), "\n")
-	return c, nil
+	return newFileCursor(absPath, filepath.Dir(absPath), contents), nil
+}
+
+// newFileCursor returns a new FileCursor over contents, positioned before the
+// first line.
+func newFileCursor(path string, dir string, contents []byte) *FileCursor {
+	return &FileCursor{
+		Path:       path,
+		dir:        dir,
+		contents:   strings.Split(string(contents), "\n"),
+		lineOffset: -1,
+		byteOffset: -1,
+	}
 }
 
 // GetLine returns the current line and true, or empty string and false if
@@ -68,6 +68,42 @@ func TestNewRootCursor_InvalidPath(t *testing.T) {
 	}
 }
 
+func TestNewRootCursorBytes(t *testing.T) {
+	rc, err := NewRootCursorBytes("inline.conf", []byte("key = val\n"), "")
+	if err != nil {
+		t.Fatalf("NewRootCursorBytes: unexpected error: %v", err)
+	}
+	if rc.File.Path != "inline.conf" {
+		t.Errorf("File.Path = %q, want %q", rc.File.Path, "inline.conf")
+	}
+	if rc.current.dir != "" {
+		t.Errorf("current.dir = %q, want empty", rc.current.dir)
+	}
+	if err := rc.AddInclude("other.conf"); err == nil {
+		t.Error("AddInclude should error without a base directory")
+	}
+}
+
+func TestNewRootCursorBytes_BaseDir(t *testing.T) {
+	dir := t.TempDir()
+	child := writeTemp(t, dir, "child.conf", "child_key = yes\n")
+
+	rc, err := NewRootCursorBytes("inline.conf", []byte("key = val\n"), dir)
+	if err != nil {
+		t.Fatalf("NewRootCursorBytes: unexpected error: %v", err)
+	}
+	if want := filepath.Join(dir, "inline.conf"); rc.File.Path != want {
+		t.Errorf("File.Path = %q, want %q", rc.File.Path, want)
+	}
+	if err := rc.AddInclude("child.conf"); err != nil {
+		t.Fatalf("AddInclude relative: %v", err)
+	}
+	fc := rc.NextInclude()
+	if fc == nil || fc.Path != child {
+		t.Errorf("NextInclude = %v, want path %q", fc, child)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // RootCursor.AddInclude
 // ---------------------------------------------------------------------------
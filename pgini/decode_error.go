@@ -0,0 +1,105 @@
+// Decode errors describe why parameters failed to decode into struct fields.
+// Decoding does not stop at the first bad value: UnmarshalSection,
+// UnmarshalFile, UnmarshalSections, and the Load functions return every
+// problem as a DecodeErrors, so callers can inspect each one with errors.As
+// instead of matching strings.
+
+package pgini
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DecodeError is a problem decoding one parameter, section, or struct.
+type DecodeError struct {
+	// Section is the name of the section, or empty for the default section.
+	Section string
+	// Key is the parameter key, or empty for a problem with a whole section
+	// or struct.
+	Key string
+	// Value is the raw value that failed to decode, or empty when the key is
+	// missing.
+	Value string
+	// Origin is where Value was defined, or zero when the key is missing or
+	// Value is a default.
+	Origin Location
+	// Field is the struct field, as a Go selector such as "Port" or
+	// "TLS.CertFile" for a field of an inline struct. It is empty for a
+	// parameter or section that no field decodes, or a Validate error.
+	Field string
+	// Type is the Go type decoded into: the field's type, or the struct's for
+	// a Validate error. It is nil for a parameter or section that no field
+	// decodes.
+	Type reflect.Type
+	// Err is the reason.
+	Err error
+}
+
+// Error formats the error as
+// `path:line:column: section "NAME": key "KEY": field NAME: reason`, leaving
+// out the parts that are unknown or empty.
+func (e *DecodeError) Error() string {
+	var b strings.Builder
+	if !e.Origin.IsZero() {
+		fmt.Fprintf(&b, "%s: ", e.Origin)
+	}
+	if e.Section != "" {
+		fmt.Fprintf(&b, "section %q: ", e.Section)
+	}
+	if e.Key != "" {
+		fmt.Fprintf(&b, "key %q: ", e.Key)
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, "field %s: ", e.Field)
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+// Unwrap returns the reason.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeErrors lists every DecodeError found while decoding, in section
+// order, and then field and parameter order within each section.
+type DecodeErrors []*DecodeError
+
+// Error formats each error on its own line.
+func (e DecodeErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the individual errors for use with errors.Is and errors.As.
+func (e DecodeErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// add appends the entries of err to e when err is a DecodeErrors, and
+// otherwise returns err, which is a problem with the struct itself, such as
+// an invalid tag, that stops decoding.
+func (e *DecodeErrors) add(err error) error {
+	if errs, ok := err.(DecodeErrors); ok {
+		*e = append(*e, errs...)
+		return nil
+	}
+	return err
+}
+
+// err returns e as an error, or nil when it is empty.
+func (e DecodeErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,184 @@
+package pgini
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// requireDecodeErrors asserts err wraps a DecodeErrors and returns it.
+func requireDecodeErrors(t *testing.T, err error) DecodeErrors {
+	t.Helper()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	var derrs DecodeErrors
+	if !errors.As(err, &derrs) {
+		t.Fatalf("error %T (%v) does not wrap a DecodeErrors", err, err)
+	}
+	return derrs
+}
+
+type decodeDatabase struct {
+	Host    string `ini:"host,required"`
+	Port    int    `ini:"port"`
+	Workers uint8  `ini:"workers" default:"1" validate:"min=1"`
+	Enabled bool   `ini:"enabled"`
+}
+
+type decodeConfig struct {
+	Name     string         `ini:"name"`
+	Retries  int            `ini:"retries"`
+	Database decodeDatabase `ini:"database,section"`
+}
+
+// ---------------------------------------------------------------------------
+// DecodeError — fields of each entry
+// ---------------------------------------------------------------------------
+
+func TestDecodeError_Fields(t *testing.T) {
+	dir := t.TempDir()
+	p := writeTemp(t, dir, "app.conf", strings.Join([]string{
+		"retries = many",
+		"",
+		"[database]",
+		"port = 54x2",
+		"workers = 0",
+		"enabled = maybe",
+		"",
+	}, "\n"))
+	f, err := Parse(p)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	derrs := requireDecodeErrors(t, f.UnmarshalFile(&decodeConfig{}))
+	want := []DecodeError{
+		{Key: "retries", Value: "many", Origin: Location{Path: p, Line: 1, Column: 1}, Field: "Retries", Type: reflect.TypeFor[int]()},
+		{Section: "database", Key: "host", Field: "Host", Type: reflect.TypeFor[string]()},
+		{Section: "database", Key: "port", Value: "54x2", Origin: Location{Path: p, Line: 4, Column: 1}, Field: "Port", Type: reflect.TypeFor[int]()},
+		{Section: "database", Key: "workers", Value: "0", Origin: Location{Path: p, Line: 5, Column: 1}, Field: "Workers", Type: reflect.TypeFor[uint8]()},
+		{Section: "database", Key: "enabled", Value: "maybe", Origin: Location{Path: p, Line: 6, Column: 1}, Field: "Enabled", Type: reflect.TypeFor[bool]()},
+	}
+	if len(derrs) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(derrs), len(want), derrs)
+	}
+	for i, w := range want {
+		got := *derrs[i]
+		if got.Err == nil {
+			t.Errorf("error %d: Err is nil", i)
+		}
+		got.Err = nil
+		if got != w {
+			t.Errorf("error %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestDecodeError_Message(t *testing.T) {
+	f, err := ParseString("app.conf", "retries = many\n[database]\nhost = db\nworkers = 0\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	err = f.UnmarshalFile(&decodeConfig{})
+	want := strings.Join([]string{
+		`UnmarshalFile: app.conf:1:1: key "retries": field Retries: invalid integer value: "many"`,
+		`app.conf:4:1: section "database": key "workers": field Workers: 0 is less than min 1`,
+	}, "\n")
+	if err == nil || err.Error() != want {
+		t.Errorf("error mismatch\n--- got ---\n%v\n--- want ---\n%s", err, want)
+	}
+}
+
+func TestDecodeError_Unwrap(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	derrs := DecodeErrors{
+		{Key: "a", Err: errors.New("first")},
+		{Key: "b", Err: sentinel},
+	}
+	if !errors.Is(derrs, sentinel) {
+		t.Error("errors.Is should find the reason of an entry")
+	}
+	var derr *DecodeError
+	if !errors.As(derrs, &derr) || derr.Key != "a" {
+		t.Errorf("errors.As should find the first entry, got %+v", derr)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// DecodeErrors — sections, validators and unknown keys
+// ---------------------------------------------------------------------------
+
+func TestDecodeErrors_UnmarshalSections(t *testing.T) {
+	f, err := ParseString("app.conf", "[db_a]\nhost = a\nport = x\n[db_b]\nhost = b\n[db_c]\nport = y\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	got, err := UnmarshalSections[decodeDatabase](f)
+	derrs := requireDecodeErrors(t, err)
+	var sections []string
+	for _, e := range derrs {
+		sections = append(sections, e.Section+"."+e.Key)
+	}
+	if want := "db_a.port db_c.host db_c.port"; strings.Join(sections, " ") != want {
+		t.Errorf("errors for %q, want %q", sections, want)
+	}
+	if len(got) != 1 || got["db_b"] == nil {
+		t.Errorf("expected only db_b, got %v", got)
+	}
+}
+
+func TestDecodeErrors_Validator(t *testing.T) {
+	f, err := ParseString("app.conf", "name = app\n[database]\nprimary = 1\nreplica = 1\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	derrs := requireDecodeErrors(t, f.UnmarshalFile(&checkedConfig{}))
+	if len(derrs) != 1 {
+		t.Fatalf("got %d errors, want 1: %v", len(derrs), derrs)
+	}
+	e := derrs[0]
+	if e.Section != "database" || e.Key != "" || e.Field != "" || e.Type != reflect.TypeFor[checkedDatabase]() {
+		t.Errorf("got %+v, want a Validate error for checkedDatabase in [database]", e)
+	}
+}
+
+func TestDecodeErrors_Strict(t *testing.T) {
+	f, err := ParseString("app.conf", "nmae = app\n[cache]\nsize = 1\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	derrs := requireDecodeErrors(t, f.UnmarshalFile(&decodeConfig{}, WithStrict()))
+	want := []string{
+		`app.conf:1:1: key "nmae": unknown key`,
+		`section "database": key "host": field Host: required key is missing`,
+		`app.conf:2:1: section "cache": unknown section`,
+	}
+	if len(derrs) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(derrs), len(want), derrs)
+	}
+	for i, w := range want {
+		if derrs[i].Error() != w {
+			t.Errorf("error %d = %q, want %q", i, derrs[i], w)
+		}
+	}
+	if derrs[0].Value != "app" || derrs[0].Type != nil {
+		t.Errorf("unknown key error = %+v, want Value app and no Type", derrs[0])
+	}
+}
+
+func TestDecodeErrors_BadTagIsNotDecodeError(t *testing.T) {
+	type bad struct {
+		Port int `ini:"port,bogus"`
+	}
+	f, err := ParseString("app.conf", "port = 1\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	err = f.UnmarshalSection("", &bad{})
+	var derrs DecodeErrors
+	if err == nil || errors.As(err, &derrs) {
+		t.Errorf("expected a plain error for an invalid tag, got %v", err)
+	}
+}
@@ -0,0 +1,409 @@
+// Document is a lossless, comment-preserving view of a single PGINI file. It
+// keeps every line of the source, along with the byte spans of each token, so
+// that a program can edit values through the regular IniFile API and write the
+// file back with its comments, blank lines, separators, quoting style and
+// include directives intact.
+//
+// Documents are built alongside the regular parser: while parsing, each line
+// of the root file is recorded and tokenized with the same scan helpers.
+
+package pgini
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// Document pairs a parsed IniFile with the concrete syntax of its root file.
+// Edit values through File (e.g. Section.SetParam, Section.RemoveParam,
+// IniFile.AddSection); MarshalIni writes the root file back, changing only the
+// lines needed to reflect those edits.
+type Document struct {
+	// File holds the parsed sections and parameters, including those from
+	// included files.
+	File *IniFile
+	// The root file's cursor, whose lines are recorded
+	source *FileCursor
+	// Every line of the root file, in order
+	lines []*docLine
+	// Effective values at parse time: section name -> key -> value
+	baseline map[string]map[string]string
+	// Keys whose effective value at parse time came from an included file,
+	// which overrides any definition in the root file: section name -> key
+	included map[string]map[string]bool
+}
+
+// lineKind classifies a line of a Document.
+type lineKind int
+
+const (
+	// blankLine is empty or whitespace-only.
+	blankLine lineKind = iota
+	// commentLine holds only a comment.
+	commentLine
+	// sectionLine is a [section] header.
+	sectionLine
+	// paramLine is a key-value parameter.
+	paramLine
+	// includeLine is an include, include_if_exists or include_dir directive.
+	includeLine
+	// rawLine failed to parse and is kept verbatim.
+	rawLine
+)
+
+// docLine is a single line of a Document with the byte spans of its tokens.
+// Spans that do not apply to the line's kind are zero.
+type docLine struct {
+	kind lineKind
+	// raw is the line exactly as it appears in the source, without its "\n".
+	raw string
+	// section is the lowercase name of the section in effect after the line.
+	section string
+	// name is the section name, parameter key, or include directive, as written.
+	nameStart, nameEnd int
+	// sep is the parameter separator ('=' or ':'), or 0 when omitted.
+	sep byte
+	// value is the decoded parameter value or include path.
+	value                string
+	valueStart, valueEnd int
+	quoted               bool
+	// commentStart is the position of the trailing comment delimiter, or -1.
+	commentStart int
+}
+
+// ParseDocument parses the PGINI file at filePath (and any included files)
+// and returns a Document of the root file.
+func ParseDocument(filePath string, opts ...ParseOption) (*Document, error) {
+	rootCursor, err := NewRootCursor(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return parseDocument(rootCursor, newParseOptions(opts))
+}
+
+// ParseDocumentReader parses PGINI contents read from r and returns a Document.
+// See ParseReader for how name and include directives are handled.
+func ParseDocumentReader(name string, r io.Reader, opts ...ParseOption) (*Document, error) {
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", name, err)
+	}
+	o := newParseOptions(opts)
+	rootCursor, err := NewRootCursorBytes(name, contents, o.baseDir)
+	if err != nil {
+		return nil, err
+	}
+	return parseDocument(rootCursor, o)
+}
+
+// ParseDocumentFS parses the PGINI file at name within fsys and returns a
+// Document. See ParseFS for how includes resolve.
+func ParseDocumentFS(fsys fs.FS, name string, opts ...ParseOption) (*Document, error) {
+	rootCursor, err := NewRootCursorFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return parseDocument(rootCursor, newParseOptions(opts))
+}
+
+// parseDocument parses rootCursor while recording the lines of its root file.
+// In recovery mode, lines that fail to parse are kept verbatim and the
+// Document is returned together with a ParseErrors.
+func parseDocument(rootCursor *RootCursor, o *parseOptions) (*Document, error) {
+	d := &Document{source: rootCursor.current}
+	docOpts := *o
+	docOpts.doc = d
+
+	f, err := parseRoot(rootCursor, &docOpts)
+	if f == nil {
+		return nil, err
+	}
+	d.File = f
+	d.baseline = snapshotValues(f)
+	d.included = make(map[string]map[string]bool)
+	for _, s := range f.Sections() {
+		for _, p := range s.Params() {
+			if p.Origin.Path != d.source.Path {
+				if d.included[s.Name] == nil {
+					d.included[s.Name] = make(map[string]bool)
+				}
+				d.included[s.Name][p.Name] = true
+			}
+		}
+	}
+	return d, err
+}
+
+// MarshalIni produces the root file with every edit made through File since
+// parsing. Unedited lines are written back byte for byte:
+//   - a changed value rewrites only the value token of the key's last
+//     definition in the root file, keeping its quoting style and comment,
+//     unless an include directive after it sets the key again
+//   - a removed parameter drops every line that defines it
+//   - a removed section drops every line within it
+//   - a new parameter is added after the last line of its section
+//   - a new section is appended to the end of the file
+//
+// Changed values that were defined only by included files, or that an included
+// file sets after their last definition in the root file, are written to the
+// root file as new parameters, after the last line of their section.
+func (d *Document) MarshalIni() ([]byte, error) {
+	if d.File == nil {
+		return nil, nil
+	}
+
+	// Locate each key's last definition and each section's last line that
+	// belongs to it, in the root file.
+	lastDef := make(map[string]map[string]int)
+	anchor := make(map[string]int)
+	for i, l := range d.lines {
+		switch l.kind {
+		case paramLine:
+			if lastDef[l.section] == nil {
+				lastDef[l.section] = make(map[string]int)
+			}
+			lastDef[l.section][strings.ToLower(l.name())] = i
+			anchor[l.section] = i
+		case sectionLine, includeLine:
+			anchor[l.section] = i
+		}
+	}
+	// A root definition overridden by an include is left as it is: rewriting
+	// it would not change the effective value.
+	for section, keys := range d.included {
+		for key := range keys {
+			delete(lastDef[section], key)
+		}
+	}
+
+	// Collect new and changed parameters without a definition in the root
+	// file, per section, in insertion order.
+	added := make(map[string][]*Param)
+	for _, s := range d.File.Sections() {
+		for _, p := range s.Params() {
+			old, existed := d.baseline[s.Name][p.Name]
+			_, inRoot := lastDef[s.Name][p.Name]
+			if !inRoot && (!existed || old != p.Value) {
+				added[s.Name] = append(added[s.Name], p)
+			}
+		}
+	}
+
+	// A default section without lines of its own gets its new parameters
+	// before the first section header, ahead of the blank lines preceding it.
+	if _, ok := anchor[""]; !ok && len(added[""]) > 0 {
+		idx := slices.IndexFunc(d.lines, func(l *docLine) bool { return l.kind == sectionLine })
+		if idx < 0 {
+			idx = len(d.lines)
+		}
+		for idx > 0 && d.lines[idx-1].kind == blankLine {
+			idx--
+		}
+		anchor[""] = idx - 1
+	}
+
+	// The final empty line stands for the file's trailing newline, which is
+	// kept at the very end of the output.
+	lines := d.lines
+	trailing := len(lines) > 0 && lines[len(lines)-1].raw == ""
+	if trailing {
+		lines = lines[:len(lines)-1]
+	}
+
+	var out []string
+	if idx, ok := anchor[""]; ok && idx < 0 {
+		paramLines, err := marshalParams(added[""])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, paramLines...)
+		delete(added, "")
+	}
+	for i, l := range lines {
+		s := d.File.GetSection(l.section)
+		if s == nil {
+			// The whole section was removed.
+			continue
+		}
+
+		text := l.raw
+		if l.kind == paramLine {
+			key := strings.ToLower(l.name())
+			p, ok := s.GetParam(key)
+			if !ok {
+				continue
+			}
+			if last, ok := lastDef[l.section][key]; ok && last == i && p.Value != d.baseline[l.section][key] {
+				text = l.withValue(p.Value)
+			}
+		}
+		out = append(out, text)
+
+		if idx, ok := anchor[l.section]; ok && idx == i && len(added[l.section]) > 0 {
+			paramLines, err := marshalParams(added[l.section])
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, paramLines...)
+			delete(added, l.section)
+		}
+	}
+
+	// Append new sections, and parameters that found no place above. The
+	// default section needs a [default] header once another is in effect.
+	inEffect := ""
+	if len(d.lines) > 0 {
+		inEffect = d.lines[len(d.lines)-1].section
+	}
+	for _, s := range d.File.Sections() {
+		_, existed := d.baseline[s.Name]
+		params, pending := added[s.Name]
+		if existed && !pending {
+			continue
+		}
+		if len(out) > 0 && out[len(out)-1] != "" {
+			out = append(out, "")
+		}
+		switch {
+		case s.Name != "":
+			out = append(out, "["+s.Name+"]")
+		case inEffect != "":
+			out = append(out, "[default]")
+		}
+		inEffect = s.Name
+		paramLines, err := marshalParams(params)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, paramLines...)
+	}
+
+	if trailing {
+		out = append(out, "")
+	}
+	return []byte(strings.Join(out, "\n")), nil
+}
+
+// name returns the line's section name, parameter key, or include directive,
+// as written.
+func (l *docLine) name() string {
+	return l.raw[l.nameStart:l.nameEnd]
+}
+
+// withValue returns the line's raw text with its value token replaced by
+// value. A quoted value stays quoted; an unquoted value is quoted only when
+// value requires it.
+func (l *docLine) withValue(value string) string {
+	token := value
+	if l.quoted || !unquotedValueRe.MatchString(value) {
+		token = "'" + pginiEscape(value) + "'"
+	}
+	rest := l.raw[l.valueEnd:]
+	if rest != "" && isComment(rune(rest[0])) {
+		// An empty value directly followed by a comment needs a separator.
+		token += " "
+	}
+	return l.raw[:l.valueStart] + token + rest
+}
+
+// marshalParams formats each parameter as a PGINI line.
+func marshalParams(params []*Param) ([]string, error) {
+	lines := make([]string, 0, len(params))
+	for _, p := range params {
+		text, err := p.MarshalIni()
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, string(text))
+	}
+	return lines, nil
+}
+
+// snapshotValues copies the current value of every parameter in f.
+func snapshotValues(f *IniFile) map[string]map[string]string {
+	values := make(map[string]map[string]string)
+	for _, s := range f.Sections() {
+		values[s.Name] = make(map[string]string)
+		for _, p := range s.Params() {
+			values[s.Name][p.Name] = p.Value
+		}
+	}
+	return values
+}
+
+// recordDocLine appends line to d, if not nil, when cursor is its root file.
+// section is the section in effect after the line, and parseErr is the error
+// from parsing it.
+func recordDocLine(d *Document, cursor *FileCursor, line string, section *Section, parseErr error) {
+	if d == nil || cursor != d.source {
+		return
+	}
+	l := &docLine{kind: rawLine, raw: line, commentStart: -1}
+	if section != nil {
+		l.section = section.Name
+	}
+	if parseErr == nil {
+		tokenizeLine(cursor, l)
+	}
+	d.lines = append(d.lines, l)
+}
+
+// tokenizeLine fills in the kind and token spans of l, whose raw text has
+// already been accepted by the parser.
+func tokenizeLine(cursor *FileCursor, l *docLine) {
+	line := l.raw
+	pos := skipWSP(line, 0)
+
+	switch {
+	case pos >= len(line):
+		l.kind = blankLine
+		return
+	case isComment(rune(line[pos])):
+		l.kind = commentLine
+		l.commentStart = pos
+		return
+	case line[pos] == '[':
+		l.kind = sectionLine
+		pos = skipWSP(line, pos+1)
+		l.nameStart = pos
+		_, pos = scanIdentifier(line, pos)
+		l.nameEnd = pos
+		pos = skipWSP(line, pos) + 1 // skip ']'
+	default:
+		l.nameStart = pos
+		ident, newPos := scanIdentifier(line, pos)
+		l.nameEnd = newPos
+		pos = skipWSP(line, newPos)
+
+		switch strings.ToLower(ident) {
+		case "include", "include_if_exists", "include_dir":
+			l.kind = includeLine
+			l.valueStart = pos
+			l.value, pos, _ = scanQuotedPath(cursor, line, pos)
+			l.valueEnd = pos
+			l.quoted = true
+		default:
+			l.kind = paramLine
+			if pos < len(line) && (line[pos] == '=' || line[pos] == ':') {
+				l.sep = line[pos]
+				pos = skipWSP(line, pos+1)
+			}
+			l.valueStart = pos
+			if pos < len(line) && line[pos] == '\'' {
+				l.value, pos, _ = scanQuotedValue(cursor, line, pos)
+				l.quoted = true
+			} else {
+				l.value, pos = scanUnquotedValue(line, pos)
+			}
+			l.valueEnd = pos
+		}
+	}
+
+	pos = skipWSP(line, pos)
+	if pos < len(line) && isComment(rune(line[pos])) {
+		l.commentStart = pos
+	}
+}
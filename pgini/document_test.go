@@ -0,0 +1,275 @@
+package pgini
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// requireDocument parses content as a Document named doc.conf or fails the test.
+func requireDocument(t *testing.T, content string) *Document {
+	t.Helper()
+	d, err := ParseDocumentReader("doc.conf", strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParseDocumentReader: %v", err)
+	}
+	return d
+}
+
+// requireMarshal marshals d and compares the result to want.
+func requireMarshal(t *testing.T, d *Document, want string) {
+	t.Helper()
+	got, err := d.MarshalIni()
+	if err != nil {
+		t.Fatalf("MarshalIni: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("MarshalIni mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Round trip — unedited documents are written back byte for byte
+// ---------------------------------------------------------------------------
+
+func TestDocument_RoundTrip_Testdata(t *testing.T) {
+	err := filepath.WalkDir(unitsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || strings.Contains(path, "errors") {
+			return err
+		}
+		t.Run(path, func(t *testing.T) {
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, err := ParseDocument(path)
+			if err != nil {
+				t.Fatalf("ParseDocument: %v", err)
+			}
+			requireMarshal(t, d, string(want))
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDocument_RoundTrip_NoTrailingNewline(t *testing.T) {
+	d := requireDocument(t, "a = 1\n[s]\nb = 2")
+	requireMarshal(t, d, "a = 1\n[s]\nb = 2")
+}
+
+// ---------------------------------------------------------------------------
+// Edits through the IniFile API
+// ---------------------------------------------------------------------------
+
+const documentSource = `# Application settings
+name  :  myapp      ; the app name
+
+[database]
+# connection
+host = 'db.internal'   # primary
+port=5432
+port = 5433
+password = 'p@ss word'
+empty =
+
+[cache]
+enabled = on
+`
+
+func TestDocument_SetParam_ChangesOnlyValue(t *testing.T) {
+	d := requireDocument(t, documentSource)
+	db := d.File.GetSection("database")
+	db.SetParam("host", "db2.internal")
+	db.SetParam("port", "6543")
+	d.File.GetSection("").SetParam("name", "other app")
+
+	want := strings.NewReplacer(
+		"'db.internal'", "'db2.internal'",
+		"port = 5433", "port = 6543",
+		"myapp      ;", "'other app'      ;",
+	).Replace(documentSource)
+	requireMarshal(t, d, want)
+}
+
+func TestDocument_SetParam_EmptyValueBeforeComment(t *testing.T) {
+	d := requireDocument(t, "a = # note\nb =\n")
+	d.File.GetSection("").SetParam("a", "x")
+	d.File.GetSection("").SetParam("b", "y")
+	requireMarshal(t, d, "a = x # note\nb =y\n")
+}
+
+func TestDocument_SetParam_EscapesQuotedValue(t *testing.T) {
+	d := requireDocument(t, "msg = 'hi'\n")
+	d.File.GetSection("").SetParam("msg", "it's\n")
+	requireMarshal(t, d, `msg = 'it\'s\n'`+"\n")
+}
+
+func TestDocument_SetParam_NewParams(t *testing.T) {
+	d := requireDocument(t, documentSource)
+	d.File.GetSection("database").SetParam("sslmode", "require")
+	d.File.GetSection("cache").SetParam("ttl", "60")
+	d.File.GetSection("").SetParam("debug", "true")
+
+	want := strings.NewReplacer(
+		"; the app name\n", "; the app name\ndebug = true\n",
+		"empty =\n", "empty =\nsslmode = require\n",
+		"enabled = on\n", "enabled = on\nttl = 60\n",
+	).Replace(documentSource)
+	requireMarshal(t, d, want)
+}
+
+func TestDocument_NewDefaultParam_BeforeFirstHeader(t *testing.T) {
+	d := requireDocument(t, "# header comment\n\n[s]\nk = v\n")
+	d.File.GetSection("").SetParam("top", "1")
+	requireMarshal(t, d, "# header comment\ntop = 1\n\n[s]\nk = v\n")
+
+	d = requireDocument(t, "[s]\nk = v\n")
+	d.File.GetSection("").SetParam("top", "1")
+	requireMarshal(t, d, "top = 1\n[s]\nk = v\n")
+}
+
+func TestDocument_RemoveParam_DropsEveryDefinition(t *testing.T) {
+	d := requireDocument(t, documentSource)
+	d.File.GetSection("database").RemoveParam("port")
+	want := strings.Replace(documentSource, "port=5432\nport = 5433\n", "", 1)
+	requireMarshal(t, d, want)
+}
+
+func TestDocument_RemoveSection_DropsItsLines(t *testing.T) {
+	d := requireDocument(t, documentSource)
+	d.File.RemoveSection("cache")
+	want := strings.Replace(documentSource, "[cache]\nenabled = on\n", "", 1)
+	requireMarshal(t, d, want)
+}
+
+func TestDocument_AddSection_AppendsAtEnd(t *testing.T) {
+	d := requireDocument(t, documentSource)
+	s, _ := d.File.AddSection("Queue")
+	s.SetParam("url", "amqp://localhost")
+	d.File.AddSection("empty_section")
+	want := documentSource + "\n[queue]\nurl = amqp://localhost\n\n[empty_section]\n"
+	requireMarshal(t, d, want)
+}
+
+func TestDocument_EmptySource(t *testing.T) {
+	d := requireDocument(t, "")
+	d.File.GetSection("").SetParam("a", "1")
+	requireMarshal(t, d, "a = 1\n")
+
+	// The blank line of the source is kept.
+	d = requireDocument(t, "\n")
+	s, _ := d.File.AddSection("s")
+	s.SetParam("b", "2")
+	requireMarshal(t, d, "\n[s]\nb = 2\n")
+}
+
+func TestDocument_NewDefaultParam_AfterHeaders(t *testing.T) {
+	// A default section whose only lines come from an include is reopened.
+	fsys := fstest.MapFS{
+		"main.conf":     {Data: []byte("include 'defaults.conf'\n")},
+		"defaults.conf": {Data: []byte("a = 1\n[s]\nb = 2\n")},
+	}
+	d, err := ParseDocumentFS(fsys, "main.conf")
+	if err != nil {
+		t.Fatalf("ParseDocumentFS: %v", err)
+	}
+	d.File.GetSection("").SetParam("a", "9")
+	requireMarshal(t, d, "include 'defaults.conf'\n\n[default]\na = 9\n")
+}
+
+// ---------------------------------------------------------------------------
+// Includes
+// ---------------------------------------------------------------------------
+
+func TestDocument_IncludesKeptAsDirectives(t *testing.T) {
+	path := unitPath("includes/14_include.conf")
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := ParseDocument(path)
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	// Included params are visible, but only the root file is written.
+	requireParam(t, d.File.GetSection(""), "included_key", "included_value")
+	d.File.GetSection("").SetParam("after", "changed")
+	requireMarshal(t, d, strings.Replace(string(want), "after = final", "after = changed", 1))
+}
+
+func TestDocument_ChangedIncludedValue_WrittenToRoot(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.conf":  {Data: []byte("[s]\ninclude 'child.conf'\nlocal = 1\n")},
+		"child.conf": {Data: []byte("inherited = a\n")},
+	}
+	d, err := ParseDocumentFS(fsys, "main.conf")
+	if err != nil {
+		t.Fatalf("ParseDocumentFS: %v", err)
+	}
+	d.File.GetSection("s").SetParam("inherited", "b")
+	requireMarshal(t, d, "[s]\ninclude 'child.conf'\nlocal = 1\ninherited = b\n")
+}
+
+func TestDocument_ChangedValue_OverriddenByInclude(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.conf": {Data: []byte("port = 1\ninclude 'inc.conf'\n\n[s]\nkey = a\n")},
+		"inc.conf": {Data: []byte("port = 2\n")},
+	}
+	d, err := ParseDocumentFS(fsys, "app.conf")
+	if err != nil {
+		t.Fatalf("ParseDocumentFS: %v", err)
+	}
+	d.File.GetSection("").SetParam("port", "9")
+	want := "port = 1\ninclude 'inc.conf'\nport = 9\n\n[s]\nkey = a\n"
+	requireMarshal(t, d, want)
+
+	// The edit survives a re-parse, after the include sets the key again.
+	fsys["app.conf"] = &fstest.MapFile{Data: []byte(want)}
+	f, err := ParseFS(fsys, "app.conf")
+	if err != nil {
+		t.Fatalf("ParseFS: %v", err)
+	}
+	requireParam(t, f.GetSection(""), "port", "9")
+}
+
+// ---------------------------------------------------------------------------
+// Errors and recovery
+// ---------------------------------------------------------------------------
+
+func TestDocument_ParseErrors(t *testing.T) {
+	if _, err := ParseDocument(nonExistingPath("missing.conf")); err == nil {
+		t.Error("expected error for missing file")
+	}
+	if _, err := ParseDocumentReader("bad.conf", strings.NewReader("!bad\n")); err == nil {
+		t.Error("expected parse error")
+	}
+	if _, err := ParseDocumentReader("broken", errReader{}); err == nil {
+		t.Error("expected read error")
+	}
+	if _, err := ParseDocumentFS(fstest.MapFS{}, "missing.conf"); err == nil {
+		t.Error("expected error for missing fs file")
+	}
+}
+
+func TestDocument_WithRecovery_KeepsBadLines(t *testing.T) {
+	content := "a = 1\n!bad line\nb = 'open\n"
+	d, err := ParseDocumentReader("doc.conf", strings.NewReader(content), WithRecovery())
+	if err == nil {
+		t.Fatal("expected ParseErrors")
+	}
+	d.File.GetSection("").SetParam("a", "2")
+	requireMarshal(t, d, strings.Replace(content, "a = 1", "a = 2", 1))
+}
+
+func TestDocument_MarshalIni_NilFile(t *testing.T) {
+	got, err := (&Document{}).MarshalIni()
+	if err != nil || got != nil {
+		t.Errorf("MarshalIni = (%q, %v), want (nil, nil)", got, err)
+	}
+}
@@ -0,0 +1,88 @@
+// Environment overrides layer environment variables over a parsed file, so a
+// deployment can change a setting without editing the file. ApplyEnv maps
+// PREFIX_SECTION_KEY variables onto the file's parameters; an `env:"NAME"`
+// struct tag overrides a single field while decoding (see tags.go). Either
+// way, the value's Origin names the variable rather than a file position.
+
+package pgini
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ApplyEnv sets a parameter of f for each variable in environ, a list of
+// "NAME=VALUE" entries as returned by os.Environ, whose name begins with
+// prefix followed by an underscore. The rest of the name selects the section
+// and key, case-insensitively: MYAPP_DATABASE_PORT=6543 with prefix "MYAPP"
+// sets port in the [database] section. When the rest begins with the names of
+// several sections, the longest wins; when it begins with none, it is a key of
+// the default section, so MYAPP_PORT sets port there.
+//
+// Only existing sections are matched, but keys need not exist. An overridden
+// definition is kept in the parameter's Shadowed history, and the new value's
+// Origin is the variable, as in Location{Env: "MYAPP_DATABASE_PORT"}. Later
+// entries of environ override earlier ones. When a variable does not name a
+// valid key, ApplyEnv returns an error and sets nothing.
+func (f *IniFile) ApplyEnv(prefix string, environ []string) error {
+	prefix = strings.TrimSuffix(prefix, "_")
+	if prefix == "" {
+		return fmt.Errorf("ApplyEnv: prefix must not be empty")
+	}
+	prefix += "_"
+
+	// Match longer section names first, so [db_replica] wins over [db].
+	var names []string
+	for _, section := range f.Sections() {
+		if section.Name != "" {
+			names = append(names, section.Name)
+		}
+	}
+	slices.SortFunc(names, func(a, b string) int { return len(b) - len(a) })
+
+	// Check every variable before setting any, so that an error leaves f as
+	// it was.
+	type setting struct {
+		section string
+		param   *Param
+	}
+	var settings []setting
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || len(name) <= len(prefix) || !strings.EqualFold(name[:len(prefix)], prefix) {
+			continue
+		}
+		sectionName, key := envSectionKey(strings.ToLower(name[len(prefix):]), names)
+		p, err := NewParam(key, value)
+		if err != nil {
+			return fmt.Errorf("ApplyEnv: %s: %w", name, err)
+		}
+		p.Origin = Location{Env: name}
+		settings = append(settings, setting{sectionName, p})
+	}
+
+	for _, s := range settings {
+		section, err := f.AddSection(s.section)
+		if err != nil {
+			return fmt.Errorf("ApplyEnv: %s: %w", s.param.Origin.Env, err)
+		}
+		if _, err := section.setParamAt(s.param.Name, s.param.Value, s.param.Origin); err != nil {
+			return fmt.Errorf("ApplyEnv: %s: %w", s.param.Origin.Env, err)
+		}
+	}
+	return nil
+}
+
+// envSectionKey splits rest, the lowercased part of a variable name after the
+// prefix, into a section name and key, matching the first of sections, sorted
+// longest first, that rest begins with followed by an underscore and a key.
+// Otherwise rest is a key of the default section.
+func envSectionKey(rest string, sections []string) (section, key string) {
+	for _, name := range sections {
+		if key, ok := strings.CutPrefix(rest, name+"_"); ok && key != "" {
+			return name, key
+		}
+	}
+	return "", rest
+}
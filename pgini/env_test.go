@@ -0,0 +1,244 @@
+package pgini
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const envConf = `port = 8080
+
+[database]
+host = db.example.com
+port = 5432
+
+[db]
+host = db
+
+[database_replica]
+host = replica.example.com
+`
+
+// --- ApplyEnv tests ---
+
+func TestApplyEnv(t *testing.T) {
+	f, err := ParseString("env.conf", envConf)
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	err = f.ApplyEnv("MYAPP", []string{
+		"PATH=/usr/bin",
+		"MYAPP_DATABASE_PORT=6543",
+		"MYAPP_DATABASE_USER=app",
+		"MYAPP_DATABASE_REPLICA_PORT=6544",
+		"MYAPP_PORT=9090",
+		"MYAPP_LOG_LEVEL=debug",
+		"myapp_db_host=localhost",
+		"MYAPPX_PORT=1",
+		"MYAPP_INVALID",
+	})
+	if err != nil {
+		t.Fatalf("ApplyEnv: %v", err)
+	}
+
+	tests := []struct {
+		section, key, want string
+	}{
+		{"", "port", "9090"},
+		{"", "log_level", "debug"},
+		{"database", "host", "db.example.com"},
+		{"database", "port", "6543"},
+		{"database", "user", "app"},
+		{"database_replica", "port", "6544"},
+		{"db", "host", "localhost"},
+	}
+	for _, tt := range tests {
+		if got, _ := f.GetSection(tt.section).GetValue(tt.key); got != tt.want {
+			t.Errorf("[%s] %s = %q, want %q", tt.section, tt.key, got, tt.want)
+		}
+	}
+	if _, ok := f.GetSection("database").GetValue("replica_port"); ok {
+		t.Error("MYAPP_DATABASE_REPLICA_PORT should set port in [database_replica], not [database]")
+	}
+}
+
+func TestApplyEnv_Provenance(t *testing.T) {
+	f, err := ParseString("env.conf", envConf)
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	if err := f.ApplyEnv("MYAPP_", []string{"MYAPP_DATABASE_PORT=6543"}); err != nil {
+		t.Fatalf("ApplyEnv: %v", err)
+	}
+
+	p, _ := f.GetSection("database").GetParam("port")
+	if p.Origin != (Location{Env: "MYAPP_DATABASE_PORT"}) || p.Origin.String() != "$MYAPP_DATABASE_PORT" {
+		t.Errorf("Origin = %+v, want the environment variable", p.Origin)
+	}
+	if len(p.Shadowed) != 1 || p.Shadowed[0].Value != "5432" || p.Shadowed[0].Origin.Line != 5 {
+		t.Errorf("Shadowed = %+v, want the definition from env.conf line 5", p.Shadowed)
+	}
+}
+
+func TestApplyEnv_Errors(t *testing.T) {
+	f, err := ParseString("env.conf", envConf)
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	if err := f.ApplyEnv("", []string{"PORT=1"}); err == nil || !strings.Contains(err.Error(), "prefix must not be empty") {
+		t.Errorf("expected an empty prefix error, got %v", err)
+	}
+	err = f.ApplyEnv("MYAPP", []string{"MYAPP_DATABASE_PORT=6543", "MYAPP_DATABASE_WORK-MEM=4MB", "MYAPP_LOG_LEVEL=debug"})
+	if err == nil || !strings.Contains(err.Error(), "ApplyEnv: MYAPP_DATABASE_WORK-MEM: invalid parameter key") {
+		t.Errorf("expected an invalid key error, got %v", err)
+	}
+	if p, _ := f.GetSection("database").GetParam("port"); p.Value != "5432" || len(p.Shadowed) != 0 {
+		t.Errorf("port = %+v, want the file's 5432: a failed ApplyEnv sets nothing", p)
+	}
+	if _, ok := f.GetSection("").GetValue("log_level"); ok {
+		t.Error("log_level is set, want nothing set by a failed ApplyEnv")
+	}
+}
+
+func TestApplyEnv_MultiReplaced(t *testing.T) {
+	type cfg struct {
+		Hosts []string `ini:"hosts,multi"`
+	}
+	f, err := ParseString("multi.conf", "hosts = a\nhosts = b\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	if err := f.ApplyEnv("APP", []string{"APP_HOSTS=c, d"}); err != nil {
+		t.Fatalf("ApplyEnv: %v", err)
+	}
+	var got cfg
+	if err := f.UnmarshalSection("", &got); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if want := []string{"c", "d"}; !slices.Equal(got.Hosts, want) {
+		t.Errorf("Hosts = %q, want %q", got.Hosts, want)
+	}
+}
+
+// --- env struct tag tests ---
+
+type envDatabase struct {
+	Host string `ini:"host" env:"DB_HOST"`
+	Port int    `ini:"port" env:"DB_PORT" default:"5432"`
+	User string `ini:"user,required" env:"DB_USER"`
+}
+
+func TestUnmarshalSection_EnvTag(t *testing.T) {
+	f, err := ParseString("env.conf", envConf)
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		environ []string
+		want    envDatabase
+	}{
+		{"file and default", []string{"DB_USER=app"}, envDatabase{Host: "db", Port: 5432, User: "app"}},
+		{"overrides file", []string{"DB_HOST=localhost", "DB_USER=app"}, envDatabase{Host: "localhost", Port: 5432, User: "app"}},
+		{"overrides default", []string{"DB_PORT=6543", "DB_USER=app"}, envDatabase{Host: "db", Port: 6543, User: "app"}},
+		{"empty value", []string{"DB_HOST=", "DB_USER=app"}, envDatabase{Port: 5432, User: "app"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got envDatabase
+			if err := f.UnmarshalSection("db", &got, WithEnviron(tt.environ)); err != nil {
+				t.Fatalf("UnmarshalSection: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalSection_EnvTagProcessEnv(t *testing.T) {
+	t.Setenv("DB_USER", "app")
+	t.Setenv("DB_PORT", "6543")
+	f, err := ParseString("env.conf", envConf)
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	var got envDatabase
+	if err := f.UnmarshalSection("db", &got); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if want := (envDatabase{Host: "db", Port: 6543, User: "app"}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	d, err := Defaults[envDatabase]()
+	if err != nil {
+		t.Fatalf("Defaults: %v", err)
+	}
+	if d.Port != 5432 || d.User != "" {
+		t.Errorf("Defaults = %+v, want only the default port", *d)
+	}
+}
+
+func TestUnmarshalSection_EnvTagMulti(t *testing.T) {
+	type cfg struct {
+		Hosts []string `ini:"hosts,multi" env:"APP_HOSTS"`
+	}
+	f, err := ParseString("multi.conf", "hosts = a\nhosts = b\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	var got cfg
+	if err := f.UnmarshalSection("", &got, WithEnviron([]string{"APP_HOSTS=c"})); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if want := []string{"c"}; !slices.Equal(got.Hosts, want) {
+		t.Errorf("Hosts = %q, want %q", got.Hosts, want)
+	}
+}
+
+func TestUnmarshalSection_EnvTagError(t *testing.T) {
+	f, err := ParseString("env.conf", envConf)
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	derrs := requireDecodeErrors(t, f.UnmarshalSection("db", &envDatabase{}, WithEnviron([]string{"DB_USER=app", "DB_PORT=many"})))
+	want := `$DB_PORT: section "db": key "port": field Port: invalid integer value: "many"`
+	if len(derrs) != 1 || derrs[0].Error() != want {
+		t.Fatalf("got %v, want %q", derrs, want)
+	}
+	if derrs[0].Origin != (Location{Env: "DB_PORT"}) || derrs[0].Value != "many" {
+		t.Errorf("got %+v, want Origin $DB_PORT and Value many", derrs[0])
+	}
+}
+
+func TestTaggedFields_EnvErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     reflect.Type
+		wantErr string
+	}{
+		{"invalid name", reflect.TypeFor[struct {
+			Port int `ini:"port" env:"DB-PORT"`
+		}](), `field Port: invalid env tag "DB-PORT"`},
+		{"empty name", reflect.TypeFor[struct {
+			Port int `ini:"port" env:""`
+		}](), `field Port: invalid env tag ""`},
+		{"section field", reflect.TypeFor[struct {
+			Database envDatabase `ini:"database,section" env:"DB"`
+		}](), "env tag does not apply to section fields"},
+		{"inline field", reflect.TypeFor[struct {
+			Database envDatabase `ini:",inline" env:"DB"`
+		}](), "env tag does not apply to inline fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := taggedFields(tt.typ)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Format rewrites PGINI source into a single canonical style, so that files
+// written by different hands and tools read the same. It works from the
+// tokens of a Document and never changes what a file means: keys, values,
+// sections, include directives and comments all survive.
+
+package pgini
+
+import (
+	"strings"
+)
+
+// Format returns src rewritten in the canonical PGINI style:
+//   - lines have no leading or trailing whitespace
+//   - section headers are lowercase, as in "[name]"
+//   - parameters are written as "key = value", with the "=" of consecutive
+//     parameter lines aligned; keys keep their case
+//   - values are quoted only when unquotedValueRe requires it
+//   - include directives are lowercase, followed by one space and the path
+//   - comments start with "#", and trailing comments follow a single space
+//   - runs of blank lines collapse to one, and the file has no leading or
+//     trailing blank lines
+//
+// Include directives are checked for syntax but not followed. name is used
+// only in error messages. Source that does not parse is returned as an error.
+func Format(name string, src []byte) ([]byte, error) {
+	rootCursor, err := NewRootCursorBytes(name, src, "")
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseDocument(rootCursor, &parseOptions{skipIncludes: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	var block []int // indexes in out of the current run of parameter lines
+	var blockKeys []string
+	flush := func() {
+		width := 0
+		for _, k := range blockKeys {
+			width = max(width, len(k))
+		}
+		for i, idx := range block {
+			out[idx] = blockKeys[i] + strings.Repeat(" ", width-len(blockKeys[i])) + out[idx]
+		}
+		block, blockKeys = block[:0], blockKeys[:0]
+	}
+
+	for _, l := range d.lines {
+		if l.kind != paramLine {
+			flush()
+		}
+
+		switch l.kind {
+		case blankLine:
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+		case commentLine:
+			out = append(out, formatComment(l))
+		case sectionLine:
+			out = append(out, "["+strings.ToLower(l.name())+"]"+formatTrailingComment(l))
+		case includeLine:
+			out = append(out, strings.ToLower(l.name())+" '"+l.value+"'"+formatTrailingComment(l))
+		case paramLine:
+			// The key is prepended once the run's width is known.
+			block = append(block, len(out))
+			blockKeys = append(blockKeys, l.name())
+			out = append(out, " = "+formatValue(l.value)+formatTrailingComment(l))
+		}
+	}
+	flush()
+
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	if len(out) == 0 {
+		return []byte{}, nil
+	}
+	return []byte(strings.Join(out, "\n") + "\n"), nil
+}
+
+// formatValue returns value as a PGINI value token, quoted and escaped only
+// when it cannot appear unquoted.
+func formatValue(value string) string {
+	if unquotedValueRe.MatchString(value) {
+		return value
+	}
+	return "'" + pginiEscape(value) + "'"
+}
+
+// formatComment returns the comment of l, which starts at l.commentStart,
+// with a "#" delimiter and no trailing whitespace.
+func formatComment(l *docLine) string {
+	return "#" + strings.TrimRight(l.raw[l.commentStart+1:], " \t")
+}
+
+// formatTrailingComment returns the trailing comment of l preceded by a
+// space, or "" when l has none.
+func formatTrailingComment(l *docLine) string {
+	if l.commentStart < 0 {
+		return ""
+	}
+	return " " + formatComment(l)
+}
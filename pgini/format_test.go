@@ -0,0 +1,133 @@
+package pgini
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// requireFormat formats src and compares the result to want.
+func requireFormat(t *testing.T, src, want string) {
+	t.Helper()
+	got, err := Format("fmt.conf", []byte(src))
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Format mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Canonical style
+// ---------------------------------------------------------------------------
+
+func TestFormat_AlignsConsecutiveParams(t *testing.T) {
+	requireFormat(t,
+		"host=localhost\nport:5432\n  dbname   app\n\nlong_key = x\n",
+		"host   = localhost\nport   = 5432\ndbname = app\n\nlong_key = x\n")
+}
+
+func TestFormat_CommentBreaksAlignment(t *testing.T) {
+	requireFormat(t,
+		"a = 1\n# about b\nbbbb = 2\n",
+		"a = 1\n# about b\nbbbb = 2\n")
+}
+
+func TestFormat_MinimalQuoting(t *testing.T) {
+	requireFormat(t,
+		"a = 'plain'\nb = 'two words'\nc = 'it\\'s'\nd =\ne = '\\101'\n",
+		"a = plain\nb = 'two words'\nc = 'it\\'s'\nd = ''\ne = A\n")
+}
+
+func TestFormat_SectionHeaders(t *testing.T) {
+	requireFormat(t,
+		"[ MyDB ]  ; primary\nHost = x\n[DEFAULT]\n",
+		"[mydb] # primary\nHost = x\n[default]\n")
+}
+
+func TestFormat_Comments(t *testing.T) {
+	requireFormat(t,
+		"  ; note   \n#\n\tkey = v   ;trailing\n",
+		"# note\n#\nkey = v #trailing\n")
+}
+
+func TestFormat_BlankLines(t *testing.T) {
+	requireFormat(t,
+		"\n\n a = 1\n\n\n\n[s]\n \t \nb = 2\n\n\n",
+		"a = 1\n\n[s]\n\nb = 2\n")
+}
+
+func TestFormat_IncludesNotFollowed(t *testing.T) {
+	requireFormat(t,
+		"INCLUDE   'missing.conf'   # kept\ninclude_if_exists 'a b.conf'\n",
+		"include 'missing.conf' # kept\ninclude_if_exists 'a b.conf'\n")
+}
+
+func TestFormat_Empty(t *testing.T) {
+	requireFormat(t, "", "")
+	requireFormat(t, "\n\n  \n", "")
+}
+
+func TestFormat_ParseError(t *testing.T) {
+	_, err := Format("bad.conf", []byte("ok = 1\nbad = 'open\n"))
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *ParseError, got %v", err)
+	}
+	if perr.Path != "bad.conf" || perr.Line != 2 || perr.Kind != UnterminatedQuote {
+		t.Errorf("got %s (%v), want bad.conf:2 unterminated quote", perr, perr.Kind)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Testdata — formatting is idempotent and keeps meaning
+// ---------------------------------------------------------------------------
+
+func TestFormat_Testdata(t *testing.T) {
+	err := filepath.WalkDir(unitsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || strings.Contains(path, "errors") {
+			return err
+		}
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			once, err := Format(path, src)
+			if err != nil {
+				t.Fatalf("Format: %v", err)
+			}
+			twice, err := Format(path, once)
+			if err != nil {
+				t.Fatalf("Format of formatted output: %v", err)
+			}
+			if string(once) != string(twice) {
+				t.Errorf("Format is not idempotent\n--- once ---\n%s\n--- twice ---\n%s", once, twice)
+			}
+
+			// Files with includes are compared where they live, so the
+			// includes resolve the same way.
+			want, err := Parse(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseBytes(filepath.Base(path), once, WithBaseDir(filepath.Dir(path)))
+			if err != nil {
+				t.Fatalf("parse formatted output: %v", err)
+			}
+			wantIni, _ := want.MarshalIni()
+			gotIni, _ := got.MarshalIni()
+			if string(wantIni) != string(gotIni) {
+				t.Errorf("formatting changed meaning\n--- got ---\n%s\n--- want ---\n%s", gotIni, wantIni)
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -9,6 +9,7 @@ package pgini
 
 import (
 	"fmt"
+	"io/fs"
 	"iter"
 	"path"
 	"regexp"
@@ -25,6 +26,8 @@ type IniFile struct {
 	sections map[string]*Section
 	// insertion order of section names (lowercased)
 	sectionOrder []string
+	// filesystem the file and its includes were read from; nil for the OS
+	fsys fs.FS
 }
 
 // NewIniFile creates a new empty IniFile for the given path.
@@ -134,7 +137,11 @@ func (f *IniFile) MarshalIni() ([]byte, error) {
 // Section represents a named group of key-value parameters.
 // The Name is stored lowercase; an empty Name represents the default section.
 type Section struct {
-	Name       string
+	Name string
+	// Origins lists each [name] header that opened or reopened the section, in
+	// parse order. The default section is opened implicitly, so only explicit
+	// [default] headers appear.
+	Origins    []Location
 	params     map[string]*Param
 	paramOrder []string
 }
@@ -163,21 +170,39 @@ func NewSection(name string) (*Section, error) {
 
 // SetParam sets or overwrites a parameter in the section. The key is normalized
 // to lowercase per the PGINI spec (keys are case-insensitive). Duplicate keys
-// update the existing value (last occurrence wins).
+// update the existing value (last occurrence wins), and clear the parameter's
+// Shadowed history, which only records definitions from a source.
 // It returns an error if name is not a valid PGINI identifier.
 func (s *Section) SetParam(name string, value string) (*Param, error) {
+	return s.setParamAt(name, value, Location{})
+}
+
+// setParamAt sets or overwrites a parameter like SetParam, recording origin as
+// where the new value was defined. When origin is non-zero, an overwritten
+// definition with a non-zero Origin is appended to the parameter's Shadowed
+// history; a zero origin, as from SetParam, clears the history instead, so
+// that programmatic updates do not grow it.
+func (s *Section) setParamAt(name string, value string, origin Location) (*Param, error) {
 	lower := strings.ToLower(name)
 	if !identifierRe.MatchString(lower) {
 		return nil, fmt.Errorf("invalid parameter key %q: must match [A-Za-z_][A-Za-z0-9_]*", name)
 	}
 
 	if p, ok := s.params[lower]; ok {
+		switch {
+		case origin.IsZero():
+			p.Shadowed = nil
+		case !p.Origin.IsZero():
+			p.Shadowed = append(p.Shadowed, &Param{Name: p.Name, Value: p.Value, Origin: p.Origin})
+		}
 		p.Value = value
+		p.Origin = origin
 		return p, nil
 	}
 	p := &Param{
-		Name:  lower,
-		Value: value,
+		Name:   lower,
+		Value:  value,
+		Origin: origin,
 	}
 	s.params[lower] = p
 	s.paramOrder = append(s.paramOrder, lower)
@@ -265,6 +290,12 @@ func (s *Section) MarshalIni() ([]byte, error) {
 type Param struct {
 	Name  string
 	Value string
+	// Origin is where Value was defined; zero when set programmatically.
+	Origin Location
+	// Shadowed lists earlier definitions from a source that Value overrode
+	// under "last wins", oldest first. Entries have no Shadowed history of
+	// their own. Setting the value programmatically clears it.
+	Shadowed []*Param
 }
 
 // NewParam creates a new Param with the given name and value.
@@ -281,6 +312,37 @@ func NewParam(name string, value string) (*Param, error) {
 	}, nil
 }
 
+// Bool interprets Value as a boolean using the same rules as struct
+// decoding: t, true, on, y, yes, 1 or f, false, off, n, no, 0,
+// case-insensitively.
+func (p *Param) Bool() (bool, error) {
+	b, err := parseBool(p.Value)
+	if err != nil {
+		return false, fmt.Errorf("param %q: %w", p.Name, err)
+	}
+	return b, nil
+}
+
+// Int interprets Value as a signed integer using the same rules as struct
+// decoding: decimal, hexadecimal (0x prefix) or octal (0 prefix), with
+// decimal fractions floored.
+func (p *Param) Int() (int64, error) {
+	n, err := parseInt(p.Value)
+	if err != nil {
+		return 0, fmt.Errorf("param %q: %w", p.Name, err)
+	}
+	return n, nil
+}
+
+// Float interprets Value as a 64-bit floating point number.
+func (p *Param) Float() (float64, error) {
+	f, err := parseFloat(p.Value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("param %q: %w", p.Name, err)
+	}
+	return f, nil
+}
+
 // String returns a human-readable summary of the Param.
 func (p *Param) String() string {
 	return fmt.Sprintf("Param(%q, %q)", p.Name, p.Value)
@@ -340,3 +402,35 @@ func pginiEscape(s string) string {
 	}
 	return b.String()
 }
+
+// Location identifies a position within a PGINI source file, or the
+// environment variable that set a value.
+type Location struct {
+	// Path is the path of the file.
+	Path string
+	// Line is the 1-indexed line number.
+	Line int
+	// Column is the 1-indexed byte column within the line.
+	Column int
+	// Env is the name of the environment variable that set the value, for a
+	// value from the environment (see ApplyEnv and the `env` struct tag);
+	// Path, Line, and Column are then zero.
+	Env string
+}
+
+// IsZero reports whether l is the zero Location, i.e. no source position.
+func (l Location) IsZero() bool {
+	return l == Location{}
+}
+
+// String returns the location as "path:line:column", as "$NAME" for an
+// environment variable, or an empty string for the zero Location.
+func (l Location) String() string {
+	if l.IsZero() {
+		return ""
+	}
+	if l.Env != "" {
+		return "$" + l.Env
+	}
+	return fmt.Sprintf("%s:%d:%d", l.Path, l.Line, l.Column)
+}
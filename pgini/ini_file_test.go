@@ -1,6 +1,8 @@
 package pgini
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -368,6 +370,42 @@ func TestSection_SetParam_Overwrite(t *testing.T) {
 	}
 }
 
+func TestSection_SetParamAt_RecordsShadowed(t *testing.T) {
+	s, _ := NewSection("app")
+	first := Location{Path: "a.conf", Line: 1, Column: 1}
+	s.setParamAt("key", "one", first)
+	p, _ := s.setParamAt("key", "two", Location{Path: "a.conf", Line: 2, Column: 1})
+
+	if len(p.Shadowed) != 1 {
+		t.Fatalf("len(Shadowed) = %d, want 1", len(p.Shadowed))
+	}
+	if got := p.Shadowed[0]; got.Value != "one" || got.Origin != first {
+		t.Errorf("Shadowed[0] = %+v, want value %q at %v", got, "one", first)
+	}
+}
+
+func TestSection_SetParam_ClearsShadowed(t *testing.T) {
+	s, _ := NewSection("app")
+	s.setParamAt("key", "one", Location{Path: "a.conf", Line: 1, Column: 1})
+	s.setParamAt("key", "two", Location{Path: "a.conf", Line: 2, Column: 1})
+	for i := range 100 {
+		s.SetParam("key", strconv.Itoa(i))
+	}
+	p, _ := s.GetParam("key")
+	if !p.Origin.IsZero() {
+		t.Errorf("Origin = %v, want zero after programmatic SetParam", p.Origin)
+	}
+	if len(p.Shadowed) != 0 {
+		t.Errorf("Shadowed = %v, want no history after programmatic SetParam", p.Shadowed)
+	}
+
+	// A later definition from a source does not record the programmatic value.
+	p, _ = s.setParamAt("key", "env", Location{Env: "APP_KEY"})
+	if len(p.Shadowed) != 0 {
+		t.Errorf("Shadowed = %v, want no entry without a location", p.Shadowed)
+	}
+}
+
 func TestSection_SetParam_InvalidKey(t *testing.T) {
 	s, _ := NewSection("app")
 	_, err := s.SetParam("123bad", "val")
@@ -588,6 +626,70 @@ func TestNewParam_Invalid(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Param.Bool, Param.Int, Param.Float
+// ---------------------------------------------------------------------------
+
+func TestParam_Bool(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  bool
+	}{{"on", true}, {"YES", true}, {"1", true}, {"off", false}, {"f", false}} {
+		p := &Param{Name: "flag", Value: tt.value}
+		got, err := p.Bool()
+		if err != nil {
+			t.Errorf("Bool(%q) error: %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Bool(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	p := &Param{Name: "flag", Value: "maybe"}
+	if _, err := p.Bool(); err == nil || !strings.Contains(err.Error(), `"flag"`) {
+		t.Errorf("Bool(%q) error = %v, want error naming the param", p.Value, err)
+	}
+}
+
+func TestParam_Int(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  int64
+	}{{"42", 42}, {"-7", -7}, {"0x1F", 31}, {"2.9", 2}} {
+		p := &Param{Name: "n", Value: tt.value}
+		got, err := p.Int()
+		if err != nil {
+			t.Errorf("Int(%q) error: %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Int(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+
+	p := &Param{Name: "n", Value: "lots"}
+	if _, err := p.Int(); err == nil {
+		t.Errorf("Int(%q) expected error", p.Value)
+	}
+}
+
+func TestParam_Float(t *testing.T) {
+	p := &Param{Name: "ratio", Value: "0.25"}
+	got, err := p.Float()
+	if err != nil {
+		t.Fatalf("Float() error: %v", err)
+	}
+	if got != 0.25 {
+		t.Errorf("Float() = %v, want 0.25", got)
+	}
+
+	p.Value = ""
+	if _, err := p.Float(); err == nil {
+		t.Error("Float() on empty value expected error")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Param.String
 // ---------------------------------------------------------------------------
@@ -777,3 +879,28 @@ func TestSection_MarshalIni_ParamErrorPropagation(t *testing.T) {
 		t.Error("MarshalIni should propagate Param marshal errors")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Location
+// ---------------------------------------------------------------------------
+
+func TestLocation_String(t *testing.T) {
+	tests := []struct {
+		loc  Location
+		want string
+	}{
+		{Location{}, ""},
+		{Location{Path: "/etc/app.conf", Line: 3, Column: 5}, "/etc/app.conf:3:5"},
+		{Location{Env: "MYAPP_DATABASE_PORT"}, "$MYAPP_DATABASE_PORT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.loc.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if got := tt.loc.IsZero(); got != (tt.want == "") {
+				t.Errorf("IsZero() = %v, want %v", got, tt.want == "")
+			}
+		})
+	}
+}
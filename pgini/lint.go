@@ -0,0 +1,287 @@
+// Lint reports problems in PGINI files that parse, but are likely mistakes:
+// keys that silently shadow each other, reopened or empty sections, missing
+// optional includes, and values that were probably meant to be quoted. Syntax
+// errors are reported alongside them, so one pass shows everything.
+
+package pgini
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// Severity ranks a Diagnostic.
+type Severity int
+
+const (
+	// SeverityInfo is worth knowing, but usually intended.
+	SeverityInfo Severity = iota
+	// SeverityWarning is likely a mistake.
+	SeverityWarning
+	// SeverityError keeps the file from loading.
+	SeverityError
+)
+
+// String returns the lowercase name of the Severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Lint check names, as reported in Diagnostic.Check.
+const (
+	// CheckParseError is a syntax or include error.
+	CheckParseError = "parse-error"
+	// CheckShadowedKey is a definition overridden by a later one.
+	CheckShadowedKey = "shadowed-key"
+	// CheckReopenedSection is a section header for a section opened earlier.
+	CheckReopenedSection = "reopened-section"
+	// CheckEmptySection is a section without parameters.
+	CheckEmptySection = "empty-section"
+	// CheckMissingInclude is an include_if_exists target that does not exist.
+	CheckMissingInclude = "missing-include"
+	// CheckKeyCase is a key spelled with different letter case than before.
+	CheckKeyCase = "key-case"
+	// CheckNeedsQuoting is an unquoted value that a comment cut short.
+	CheckNeedsQuoting = "needs-quoting"
+)
+
+// Diagnostic is a problem found by Lint.
+type Diagnostic struct {
+	// Location is where the problem is.
+	Location
+	// Severity ranks the problem.
+	Severity Severity
+	// Check names the check that found the problem; see the Check constants.
+	Check string
+	// Msg describes the problem.
+	Msg string
+}
+
+// String formats the Diagnostic as "path:line:column: severity: message (check)".
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", d.Location, d.Severity, d.Msg, d.Check)
+}
+
+// Lint parses the PGINI file at filePath, following includes, and returns
+// every problem found, ordered by location. Parse errors do not stop Lint;
+// they are reported as SeverityError diagnostics. The error is non-nil only
+// when filePath itself cannot be read.
+func Lint(filePath string) ([]Diagnostic, error) {
+	rootCursor, err := NewRootCursor(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var missing []*ParseError
+	f, err := parseRoot(rootCursor, &parseOptions{
+		recovering:     true,
+		missingInclude: func(perr *ParseError) { missing = append(missing, perr) },
+	})
+	if f == nil {
+		return nil, err
+	}
+
+	var diags []Diagnostic
+	failed := make(map[Location]bool) // lines with parse errors; Column is 0
+	for _, perr := range rootCursor.errs {
+		diags = append(diags, Diagnostic{
+			Location: Location{Path: perr.Path, Line: perr.Line, Column: perr.Column},
+			Severity: SeverityError,
+			Check:    CheckParseError,
+			Msg:      perr.Msg,
+		})
+		failed[Location{Path: perr.Path, Line: perr.Line}] = true
+	}
+	for _, perr := range missing {
+		diags = append(diags, Diagnostic{
+			Location: Location{Path: perr.Path, Line: perr.Line, Column: perr.Column},
+			Severity: SeverityWarning,
+			Check:    CheckMissingInclude,
+			Msg:      perr.Msg,
+		})
+	}
+
+	lines, err := lintLines(rootCursor, failed)
+	if err != nil {
+		return nil, err
+	}
+	diags = append(diags, lintSections(f)...)
+	diags = append(diags, lintParams(f, lines)...)
+	diags = append(diags, lintValues(lines)...)
+
+	slices.SortStableFunc(diags, func(a, b Diagnostic) int {
+		return cmp.Or(
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+		)
+	})
+	return diags, nil
+}
+
+// lintLines tokenizes each line that parsed cleanly in every file rootCursor
+// visited, keyed by path and then 1-indexed line number.
+func lintLines(rootCursor *RootCursor, failed map[Location]bool) (map[string]map[int]*docLine, error) {
+	lines := make(map[string]map[int]*docLine)
+	for filePath := range rootCursor.visited {
+		cursor, err := rootCursor.openFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		fileLines := make(map[int]*docLine)
+		for line, ok := cursor.NextLine(); ok; line, ok = cursor.NextLine() {
+			n := cursor.lineOffset + 1
+			if failed[Location{Path: cursor.Path, Line: n}] {
+				continue
+			}
+			l := &docLine{raw: line, commentStart: -1}
+			tokenizeLine(cursor, l)
+			fileLines[n] = l
+		}
+		lines[cursor.Path] = fileLines
+	}
+	return lines, nil
+}
+
+// lintSections reports empty sections and sections reopened by a later
+// header. A reopening in the same file is a warning; one from another file
+// is usually a deliberate override.
+func lintSections(f *IniFile) []Diagnostic {
+	var diags []Diagnostic
+	for _, s := range f.Sections() {
+		if s.Name == "" || len(s.Origins) == 0 {
+			continue
+		}
+		first := s.Origins[0]
+
+		empty := true
+		for range s.Params() {
+			empty = false
+			break
+		}
+		if empty {
+			diags = append(diags, Diagnostic{
+				Location: first,
+				Severity: SeverityInfo,
+				Check:    CheckEmptySection,
+				Msg:      fmt.Sprintf("section [%s] has no parameters", s.Name),
+			})
+		}
+
+		for _, o := range s.Origins[1:] {
+			diags = append(diags, Diagnostic{
+				Location: o,
+				Severity: sameFileSeverity(o, first),
+				Check:    CheckReopenedSection,
+				Msg:      fmt.Sprintf("section [%s] reopened; first opened at %s", s.Name, refLocation(o, first)),
+			})
+		}
+	}
+	return diags
+}
+
+// lintParams reports definitions shadowed by a later one, and keys spelled
+// with different letter case than their first definition.
+func lintParams(f *IniFile, lines map[string]map[int]*docLine) []Diagnostic {
+	var diags []Diagnostic
+	for _, s := range f.Sections() {
+		for _, p := range s.Params() {
+			defs := append(slices.Clone(p.Shadowed), p)
+			for i, old := range p.Shadowed {
+				next := defs[i+1].Origin
+				diags = append(diags, Diagnostic{
+					Location: old.Origin,
+					Severity: sameFileSeverity(old.Origin, next),
+					Check:    CheckShadowedKey,
+					Msg:      fmt.Sprintf("%s is overridden at %s", keyLabel(s, p), refLocation(old.Origin, next)),
+				})
+			}
+
+			var first string
+			var firstOrigin Location
+			for _, def := range defs {
+				l := lines[def.Origin.Path][def.Origin.Line]
+				if l == nil || l.kind != paramLine {
+					continue
+				}
+				switch name := l.name(); {
+				case first == "":
+					first, firstOrigin = name, def.Origin
+				case name != first:
+					diags = append(diags, Diagnostic{
+						Location: def.Origin,
+						Severity: SeverityWarning,
+						Check:    CheckKeyCase,
+						Msg:      fmt.Sprintf("key %q differs only in case from %q at %s", name, first, refLocation(def.Origin, firstOrigin)),
+					})
+				}
+			}
+		}
+	}
+	return diags
+}
+
+// lintValues reports unquoted values that a comment delimiter cut short,
+// such as "password = abc;def" or "color = #fff".
+func lintValues(lines map[string]map[int]*docLine) []Diagnostic {
+	var diags []Diagnostic
+	for filePath, fileLines := range lines {
+		for n, l := range fileLines {
+			if l.kind != paramLine || l.quoted || l.commentStart != l.valueEnd {
+				continue
+			}
+			rest := l.raw[l.commentStart:]
+			var msg string
+			switch {
+			case l.value != "":
+				msg = fmt.Sprintf("value %q is cut off by comment %q; quote the value to keep it", l.value, rest)
+			case len(rest) > 1 && !isWSP(rune(rest[1])):
+				msg = fmt.Sprintf("value is empty because %q starts a comment; quote it if it is the value", rest)
+			default:
+				continue
+			}
+			diags = append(diags, Diagnostic{
+				Location: Location{Path: filePath, Line: n, Column: l.valueStart + 1},
+				Severity: SeverityWarning,
+				Check:    CheckNeedsQuoting,
+				Msg:      msg,
+			})
+		}
+	}
+	return diags
+}
+
+// sameFileSeverity returns SeverityWarning when a and b are in the same file,
+// and SeverityInfo otherwise.
+func sameFileSeverity(a, b Location) Severity {
+	if a.Path == b.Path {
+		return SeverityWarning
+	}
+	return SeverityInfo
+}
+
+// refLocation describes to as seen from from: just the line when both are in
+// the same file.
+func refLocation(from, to Location) string {
+	if from.Path == to.Path {
+		return fmt.Sprintf("line %d", to.Line)
+	}
+	return fmt.Sprintf("%s:%d", to.Path, to.Line)
+}
+
+// keyLabel names p for messages, qualified by its section if any.
+func keyLabel(s *Section, p *Param) string {
+	if s.Name == "" {
+		return fmt.Sprintf("key %q", p.Name)
+	}
+	return fmt.Sprintf("key %q in [%s]", p.Name, s.Name)
+}
@@ -0,0 +1,165 @@
+package pgini
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// requireLint lints filePath and returns each diagnostic as
+// "file:line:column severity check", for compact comparison.
+func requireLint(t *testing.T, filePath string) []string {
+	t.Helper()
+	diags, err := Lint(filePath)
+	if err != nil {
+		t.Fatalf("Lint: %v", err)
+	}
+	got := make([]string, len(diags))
+	for i, d := range diags {
+		got[i] = fmt.Sprintf("%s:%d:%d %s %s", filepath.Base(d.Path), d.Line, d.Column, d.Severity, d.Check)
+	}
+	return got
+}
+
+// requireDiagnostics compares got to want, in order.
+func requireDiagnostics(t *testing.T, got, want []string) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Errorf("diagnostics mismatch\n--- got ---\n%s\n--- want ---\n%s",
+			strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Lint checks
+// ---------------------------------------------------------------------------
+
+func TestLint_Clean(t *testing.T) {
+	for _, name := range []string{"05_separators.conf", "07_quoted_values.conf", "13_trailing_comments.conf"} {
+		if got := requireLint(t, unitPath(name)); len(got) != 0 {
+			t.Errorf("%s: expected no diagnostics, got %v", name, got)
+		}
+	}
+}
+
+func TestLint_ShadowedKeysAndReopenedSections(t *testing.T) {
+	requireDiagnostics(t, requireLint(t, unitPath("11_duplicates.conf")), []string{
+		"11_duplicates.conf:4:1 warning shadowed-key",
+		"11_duplicates.conf:5:1 warning shadowed-key",
+		"11_duplicates.conf:9:1 warning shadowed-key",
+		"11_duplicates.conf:10:1 warning shadowed-key",
+		"11_duplicates.conf:14:1 warning shadowed-key",
+		"11_duplicates.conf:18:1 warning reopened-section",
+	})
+}
+
+func TestLint_OverrideFromIncludeIsInfo(t *testing.T) {
+	requireDiagnostics(t, requireLint(t, unitPath("includes/14_include.conf")), []string{
+		"14_include.conf:4:1 info shadowed-key",
+	})
+}
+
+func TestLint_MissingOptionalInclude(t *testing.T) {
+	diags, err := Lint(unitPath("includes/15_include_if_exists.conf"))
+	if err != nil {
+		t.Fatalf("Lint: %v", err)
+	}
+	if len(diags) != 1 || diags[0].Check != CheckMissingInclude || diags[0].Severity != SeverityWarning {
+		t.Fatalf("expected one missing-include warning, got %v", diags)
+	}
+	if !strings.Contains(diags[0].Msg, "nonexistent_file_12345.conf") {
+		t.Errorf("message should name the target, got %q", diags[0].Msg)
+	}
+}
+
+func TestLint_KeyCase(t *testing.T) {
+	dir := t.TempDir()
+	p := writeTemp(t, dir, "case.conf", "Host = a\nhost = b\n")
+	requireDiagnostics(t, requireLint(t, p), []string{
+		"case.conf:1:1 warning shadowed-key",
+		"case.conf:2:1 warning key-case",
+	})
+}
+
+func TestLint_KeyCaseAcrossIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeTemp(t, dir, "inc.conf", "PORT = 2\n")
+	p := writeTemp(t, dir, "main.conf", "port = 1\ninclude 'inc.conf'\n")
+	requireDiagnostics(t, requireLint(t, p), []string{
+		"inc.conf:1:1 warning key-case",
+		"main.conf:1:1 info shadowed-key",
+	})
+}
+
+func TestLint_EmptySection(t *testing.T) {
+	dir := t.TempDir()
+	p := writeTemp(t, dir, "empty.conf", "a = 1\n\n[unused]\n\n[used]\nb = 2\n")
+	requireDiagnostics(t, requireLint(t, p), []string{
+		"empty.conf:3:1 info empty-section",
+	})
+}
+
+func TestLint_NeedsQuoting(t *testing.T) {
+	dir := t.TempDir()
+	p := writeTemp(t, dir, "quote.conf", strings.Join([]string{
+		"password = abc;def",
+		"color = #fff",
+		"url = http://x/#frag",
+		"empty = # just a comment",
+		"quoted = 'abc;def'",
+		"spaced = abc # comment",
+		"",
+	}, "\n"))
+	requireDiagnostics(t, requireLint(t, p), []string{
+		"quote.conf:1:12 warning needs-quoting",
+		"quote.conf:2:9 warning needs-quoting",
+		"quote.conf:3:7 warning needs-quoting",
+	})
+}
+
+func TestLint_ParseErrorsReported(t *testing.T) {
+	dir := t.TempDir()
+	p := writeTemp(t, dir, "bad.conf", "a = 1\nb = 'open\na = 2\n")
+	requireDiagnostics(t, requireLint(t, p), []string{
+		"bad.conf:1:1 warning shadowed-key",
+		"bad.conf:2:10 error parse-error",
+	})
+}
+
+func TestLint_MissingFile(t *testing.T) {
+	if _, err := Lint(nonExistingPath("missing.conf")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Diagnostic and Severity
+// ---------------------------------------------------------------------------
+
+func TestDiagnostic_String(t *testing.T) {
+	d := Diagnostic{
+		Location: Location{Path: "a.conf", Line: 3, Column: 5},
+		Severity: SeverityWarning,
+		Check:    CheckShadowedKey,
+		Msg:      "key \"x\" is overridden at line 4",
+	}
+	want := `a.conf:3:5: warning: key "x" is overridden at line 4 (shadowed-key)`
+	if got := d.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestSeverity_String(t *testing.T) {
+	for s, want := range map[Severity]string{
+		SeverityInfo:    "info",
+		SeverityWarning: "warning",
+		SeverityError:   "error",
+		Severity(99):    "unknown",
+	} {
+		if got := s.String(); got != want {
+			t.Errorf("Severity(%d).String() = %q, want %q", s, got, want)
+		}
+	}
+}
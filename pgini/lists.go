@@ -0,0 +1,163 @@
+// Lists decode slice fields from a single value, using the syntax of
+// PostgreSQL list settings such as search_path: elements are separated by a
+// comma, or by the `sep=` tag option, and surrounding whitespace is dropped.
+// An element in double quotes may contain the separator, whitespace, or a
+// doubled "" for a literal quote. Each element is decoded by the element
+// type, as a field of that type would be.
+//
+// With the `multi` tag option, repeated definitions of the key accumulate
+// into the slice, oldest first, instead of the last one winning. A value from
+// an environment variable (see env.go) replaces the definitions before it.
+
+package pgini
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// defaultListSep separates list elements when a field has no `sep=` option.
+const defaultListSep = ','
+
+// setSliceField decodes param into fieldValue, a slice, replacing its
+// contents.
+func setSliceField(fieldValue reflect.Value, param *Param, tag fieldTag) error {
+	defs := []*Param{param}
+	if tag.multi {
+		defs = append(append([]*Param{}, param.Shadowed...), param)
+		// An environment variable overrides the key rather than repeating
+		// it, so it replaces the definitions before it.
+		for i := len(defs) - 1; i > 0; i-- {
+			if defs[i].Origin.Env != "" {
+				defs = defs[i:]
+				break
+			}
+		}
+	}
+
+	var items []*Param
+	for _, def := range defs {
+		elems, err := splitList(def.Value, tag.listSep())
+		if err != nil {
+			return err
+		}
+		for _, e := range elems {
+			items = append(items, &Param{Name: param.Name, Value: e, Origin: def.Origin})
+		}
+	}
+
+	elemTag := fieldTag{name: tag.name, unit: tag.unit}
+	slice := reflect.MakeSlice(fieldValue.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := unmarshalValue(slice.Index(i), item, elemTag); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	fieldValue.Set(slice)
+	return nil
+}
+
+// formatSliceField formats fieldValue, a slice, as a list. An empty or nil
+// slice formats as an empty value.
+func formatSliceField(fieldValue reflect.Value, tag fieldTag) (string, error) {
+	elemTag := fieldTag{name: tag.name, unit: tag.unit}
+	elems := make([]string, fieldValue.Len())
+	for i := range elems {
+		elem := fieldValue.Index(i)
+		if elem.Kind() == reflect.String && !implementsMarshaler(elem.Type()) {
+			elems[i] = elem.String()
+			continue
+		}
+		str, err := marshalValue(elem, elemTag)
+		if err != nil {
+			return "", fmt.Errorf("element %d: %w", i, err)
+		}
+		elems[i] = str
+	}
+	return joinList(elems, tag.listSep()), nil
+}
+
+// isListSep reports whether r may separate list elements: any printable
+// ASCII punctuation other than a double quote.
+func isListSep(r rune) bool {
+	return r > ' ' && r < 0x7F && r != '"' && !isLetter(r) && !isDigit(r)
+}
+
+// splitList splits raw into its elements, separated by sep. Elements may be
+// double-quoted. An empty or blank value has no elements.
+func splitList(raw string, sep rune) ([]string, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return nil, nil
+	}
+
+	var elems []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		var elem string
+		if strings.HasPrefix(s, `"`) {
+			var b strings.Builder
+			i := 1
+			for {
+				j := strings.IndexByte(s[i:], '"')
+				if j < 0 {
+					return nil, fmt.Errorf("invalid list value %q: unterminated double quote", raw)
+				}
+				b.WriteString(s[i : i+j])
+				i += j + 1
+				if i < len(s) && s[i] == '"' { // "" is a literal quote
+					b.WriteByte('"')
+					i++
+					continue
+				}
+				break
+			}
+			elem = b.String()
+			s = strings.TrimLeft(s[i:], " \t")
+			if s != "" && !strings.HasPrefix(s, string(sep)) {
+				return nil, fmt.Errorf("invalid list value %q: expected %q after quoted element", raw, sep)
+			}
+		} else {
+			end := strings.IndexRune(s, sep)
+			if end < 0 {
+				end = len(s)
+			}
+			elem = strings.TrimRight(s[:end], " \t")
+			if elem == "" {
+				return nil, fmt.Errorf("invalid list value %q: empty element", raw)
+			}
+			s = s[end:]
+		}
+		elems = append(elems, elem)
+
+		if s == "" {
+			return elems, nil
+		}
+		s = s[len(string(sep)):] // drop the separator
+		if strings.TrimSpace(s) == "" {
+			return nil, fmt.Errorf("invalid list value %q: empty element", raw)
+		}
+	}
+}
+
+// joinList joins elems with sep, the inverse of splitList. Elements that are
+// empty, contain sep or a double quote, or have surrounding whitespace are
+// double-quoted. A comma is followed by a space, as PostgreSQL writes lists.
+func joinList(elems []string, sep rune) string {
+	var b strings.Builder
+	for i, e := range elems {
+		if i > 0 {
+			b.WriteRune(sep)
+			if sep == ',' {
+				b.WriteByte(' ')
+			}
+		}
+		if e == "" || strings.ContainsRune(e, sep) || strings.Contains(e, `"`) || strings.TrimSpace(e) != e {
+			b.WriteString(`"` + strings.ReplaceAll(e, `"`, `""`) + `"`)
+			continue
+		}
+		b.WriteString(e)
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package pgini
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// --- splitList tests ---
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		raw  string
+		sep  rune
+		want []string
+	}{
+		{"", ',', nil},
+		{"   ", ',', nil},
+		{"a", ',', []string{"a"}},
+		{"a,b,c", ',', []string{"a", "b", "c"}},
+		{" a , b ,c ", ',', []string{"a", "b", "c"}},
+		{`"$user", public`, ',', []string{"$user", "public"}},
+		{`"a, b", c`, ',', []string{"a, b", "c"}},
+		{`"say ""hi"""`, ',', []string{`say "hi"`}},
+		{`""`, ',', []string{""}},
+		{`" padded "`, ',', []string{" padded "}},
+		{"a b, c d", ',', []string{"a b", "c d"}},
+		{"/usr/bin:/bin", ':', []string{"/usr/bin", "/bin"}},
+		{"a,b;c", ';', []string{"a,b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := splitList(tt.raw, tt.sep)
+			if err != nil {
+				t.Fatalf("splitList(%q): %v", tt.raw, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitList(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitList_Errors(t *testing.T) {
+	tests := []struct {
+		raw     string
+		wantErr string
+	}{
+		{"a,,b", "empty element"},
+		{",a", "empty element"},
+		{"a,", "empty element"},
+		{"a, ", "empty element"},
+		{`"open, b`, "unterminated double quote"},
+		{`"a"b, c`, "after quoted element"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			_, err := splitList(tt.raw, ',')
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("splitList(%q) error = %v, want %q", tt.raw, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// --- joinList tests ---
+
+func TestJoinList(t *testing.T) {
+	tests := []struct {
+		elems []string
+		sep   rune
+		want  string
+	}{
+		{nil, ',', ""},
+		{[]string{"a"}, ',', "a"},
+		{[]string{"a", "b", "c"}, ',', "a, b, c"},
+		{[]string{"$user", "public"}, ',', "$user, public"},
+		{[]string{"a, b", "c"}, ',', `"a, b", c`},
+		{[]string{`say "hi"`}, ',', `"say ""hi"""`},
+		{[]string{""}, ',', `""`},
+		{[]string{" padded "}, ',', `" padded "`},
+		{[]string{"/usr/bin", "/bin"}, ':', "/usr/bin:/bin"},
+		{[]string{"a,b", "c"}, ';', "a,b;c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got := joinList(tt.elems, tt.sep)
+			if got != tt.want {
+				t.Errorf("joinList(%q) = %q, want %q", tt.elems, got, tt.want)
+			}
+			back, err := splitList(got, tt.sep)
+			if err != nil || !slices.Equal(back, tt.elems) {
+				t.Errorf("splitList(%q) = %q, %v; want %q", got, back, err, tt.elems)
+			}
+		})
+	}
+}
@@ -1,105 +1,394 @@
 // Marshaling encodes Go structs into IniFile sections using struct field tags.
 //
-// Fields are mapped via `ini:"KEY"` tags. Fields without an `ini` tag or with
-// an empty tag value are skipped. For primitive types (string, bool, int*,
-// uint*, float*), a default formatter is used. For other types, a custom
-// Marshal<FieldName> method must exist on the struct.
+// Fields are mapped via `ini:"KEY"` tags; see tags.go for tag options. Fields
+// without an `ini` tag or with an empty tag value are skipped. A custom
+// Marshal<FieldName> method on the struct takes precedence; then a field type
+// implementing IniMarshaler or encoding.TextMarshaler encodes itself.
+// time.Duration fields, and integer fields tagged with a memory unit, are
+// written in the largest exact PostgreSQL unit. Slice fields are written as a
+// list value (see lists.go). Nil pointer fields are skipped.
+// For primitive types (string, bool, int*, uint*, float*), a default formatter
+// is used.
 
 package pgini
 
 import (
+	"encoding"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 )
 
+// IniMarshaler is implemented by types that encode themselves as a PGINI
+// parameter. MarshalIniParam receives a Param named for the field's key and
+// sets its Value. It takes precedence over encoding.TextMarshaler.
+type IniMarshaler interface {
+	MarshalIniParam(p *Param) error
+}
+
 // MarshalSection encodes the exported fields of structPtr into the named section,
 // creating the section if it does not exist. structPtr must be a pointer to a struct.
 // Fields are matched by their `ini:"KEY"` tag. Fields without an `ini` tag
-// or with an empty tag value are skipped.
+// or with an empty tag value are skipped, as are section fields and nil
+// pointer fields. The entries of an `ini:",remain"` field follow the other
+// fields, in sorted key order.
+//
+// structPtr may instead be a pointer to a map[string]string or map[string]any,
+// whose entries are written in sorted key order. A map[string]any value is
+// formatted as a field of its type would be; nil values are skipped.
 func (f *IniFile) MarshalSection(name string, structPtr any) error {
-	// Unwrap the pointer to get the underlying struct value and its type descriptor.
+	// Unwrap the pointer to get the underlying struct, or map, value.
 	structValue := reflect.ValueOf(structPtr)
-	if structValue.Kind() != reflect.Pointer || structValue.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("MarshalSection: data must be a pointer to a struct, got %T", structPtr)
+	if !isTarget(structValue) {
+		return fmt.Errorf("MarshalSection: data must be a pointer to a struct or to a map[string]string or map[string]any, got %T", structPtr)
 	}
 	structValue = structValue.Elem()
-	structType := structValue.Type()
 
 	section, err := f.AddSection(name)
 	if err != nil {
 		return fmt.Errorf("MarshalSection: %w", err)
 	}
+	if structValue.Kind() == reflect.Map {
+		if err := marshalMap(section, structValue, nil); err != nil {
+			return fmt.Errorf("MarshalSection: %w", err)
+		}
+		return nil
+	}
+
+	if err := marshalSection(section, structValue); err != nil {
+		return fmt.Errorf("MarshalSection: %w", err)
+	}
+	return nil
+}
+
+// MarshalFile encodes the exported fields of structPtr into the whole file,
+// the inverse of UnmarshalFile. structPtr must be a pointer to a struct.
+// Fields tagged `ini:"NAME,section"` must be structs, and are encoded into the
+// [NAME] section as by MarshalSection. All other tagged fields are encoded
+// into the default section.
+func (f *IniFile) MarshalFile(structPtr any) error {
+	// Unwrap the pointer to get the underlying struct value and its type descriptor.
+	structValue := reflect.ValueOf(structPtr)
+	if structValue.Kind() != reflect.Pointer || structValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("MarshalFile: data must be a pointer to a struct, got %T", structPtr)
+	}
+
+	if err := marshalFile(f, structValue.Elem()); err != nil {
+		return fmt.Errorf("MarshalFile: %w", err)
+	}
+	return nil
+}
+
+// Sample returns a PGINI file documenting every key of T with its default
+// value, as in PostgreSQL's postgresql.conf.sample: each parameter line is
+// commented out, so the file loads as if empty. Keys without a `default` tag
+// show their zero value; nil pointer fields and sections fields are left out.
+func Sample[T any]() ([]byte, error) {
+	t, err := Defaults[T]()
+	if err != nil {
+		return nil, fmt.Errorf("Sample: %w", err)
+	}
+	f, err := NewIniFile("sample.conf")
+	if err != nil {
+		return nil, fmt.Errorf("Sample: %w", err)
+	}
+	if err := marshalFile(f, reflect.ValueOf(t).Elem()); err != nil {
+		return nil, fmt.Errorf("Sample: %w", err)
+	}
+
+	text, err := f.MarshalIni()
+	if err != nil {
+		return nil, fmt.Errorf("Sample: %w", err)
+	}
+	var b strings.Builder
+	for line := range strings.Lines(string(text)) {
+		if line != "\n" && !strings.HasPrefix(line, "[") {
+			b.WriteString("#")
+		}
+		b.WriteString(line)
+	}
+	return []byte(b.String()), nil
+}
+
+// marshalFile encodes structValue, an addressable struct, into f, as
+// described by MarshalFile.
+func marshalFile(f *IniFile, structValue reflect.Value) error {
+	fields, err := taggedFields(structValue.Type())
+	if err != nil {
+		return err
+	}
+
+	section, err := f.AddSection("")
+	if err != nil {
+		return err
+	}
+	if err := marshalSection(section, structValue); err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		if !field.tag.isSection() {
+			continue
+		}
+		_, fieldValue, ok := fieldAt(structValue, field, false)
+		if !ok {
+			continue // a nil inline struct pointer has nothing to encode
+		}
+		switch {
+		case field.tag.sections:
+			if err := marshalSectionsField(f, field, fieldValue); err != nil {
+				return err
+			}
+		case field.tag.section:
+			if fieldValue.Kind() != reflect.Struct {
+				return fmt.Errorf("field %s: section field must be a struct, got %s", field.name, fieldValue.Type())
+			}
+
+			section, err := f.AddSection(field.tag.name)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field.name, err)
+			}
+			if err := marshalSection(section, fieldValue); err != nil {
+				return fmt.Errorf("section %q: %w", section.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// marshalSectionsField encodes each entry of a sections field, a
+// map[string]S or map[string]*S where S is a struct, into the section named
+// by its key, in key order. Nil entries are skipped.
+func marshalSectionsField(f *IniFile, field taggedField, fieldValue reflect.Value) error {
+	elemType, err := sectionsElemType(field)
+	if err != nil {
+		return err
+	}
+
+	keys := fieldValue.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+	for _, key := range keys {
+		entry := fieldValue.MapIndex(key)
+		if entry.Kind() == reflect.Pointer {
+			if entry.IsNil() {
+				continue
+			}
+			entry = entry.Elem()
+		}
+		// Map entries are not addressable, which custom Marshal methods need.
+		elem := reflect.New(elemType).Elem()
+		elem.Set(entry)
+
+		section, err := f.AddSection(key.String())
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.name, err)
+		}
+		if err := marshalSection(section, elem); err != nil {
+			return fmt.Errorf("section %q: %w", section.Name, err)
+		}
+	}
+	return nil
+}
+
+// marshalSection encodes the key fields of structValue, an addressable
+// struct, into section, followed by the entries of its remain field. Section
+// fields are skipped.
+func marshalSection(section *Section, structValue reflect.Value) error {
+	fields, err := taggedFields(structValue.Type())
+	if err != nil {
+		return err
+	}
 
-	// Iterate over each exported struct field, looking for `ini` tags.
-	for i := range structType.NumField() {
-		fieldDef := structType.Field(i) // field metadata (name, type, tags)
-		tag, ok := fieldDef.Tag.Lookup("ini")
-		if !ok || tag == "" {
+	claimed := make(map[string]string) // lowercased key to field name
+	for _, field := range fields {
+		if field.tag.isSection() || field.tag.remain {
 			continue
 		}
+		claimed[strings.ToLower(field.tag.name)] = field.name
 
-		fieldValue := structValue.Field(i) // the runtime value of this field
-		str, err := marshalField(structValue, fieldDef, fieldValue)
+		// The runtime value of this field, and the struct with its custom methods.
+		receiver, fieldValue, ok := fieldAt(structValue, field, false)
+		if !ok || fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil() {
+			continue // an unset pointer leaves the key out
+		}
+		str, err := marshalField(receiver, field, fieldValue)
 		if err != nil {
-			return fmt.Errorf("MarshalSection: field %s: %w", fieldDef.Name, err)
+			return fmt.Errorf("field %s: %w", field.name, err)
 		}
 
-		if _, err := section.SetParam(tag, str); err != nil {
-			return fmt.Errorf("MarshalSection: field %s: %w", fieldDef.Name, err)
+		if _, err := section.SetParam(field.tag.name, str); err != nil {
+			return fmt.Errorf("field %s: %w", field.name, err)
 		}
 	}
 
+	if remain, ok := remainField(fields); ok {
+		if _, mapValue, ok := fieldAt(structValue, remain, false); ok {
+			if err := marshalMap(section, mapValue, claimed); err != nil {
+				return fmt.Errorf("field %s: %w", remain.name, err)
+			}
+		}
+	}
 	return nil
 }
 
-// marshalField converts a struct field value to its PGINI string representation.
-// If a custom Marshal<FieldName> method exists on the struct, it is used.
-// Otherwise, the default formatField is used for primitive types.
+// marshalMap encodes the entries of mapValue, a map[string]string or
+// map[string]any, into section in sorted key order. Nil values are skipped. A
+// key in claimed, which maps lowercased keys to the fields that encode them,
+// is an error, since decoding would give its value to that field.
+func marshalMap(section *Section, mapValue reflect.Value, claimed map[string]string) error {
+	keys := mapValue.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+	for _, key := range keys {
+		name := key.String()
+		if other, ok := claimed[strings.ToLower(name)]; ok {
+			return fmt.Errorf("key %q collides with field %s", name, other)
+		}
+		value := mapValue.MapIndex(key)
+		if value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		}
+
+		// Strings are stored as is, as UnmarshalSection reads them back into a
+		// map, rather than escaped by formatField.
+		str := value.String()
+		if value.Kind() != reflect.String || implementsMarshaler(value.Type()) {
+			var err error
+			if str, err = marshalValue(value, fieldTag{name: name}); err != nil {
+				return fmt.Errorf("key %q: %w", name, err)
+			}
+		}
+		if _, err := section.SetParam(name, str); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshalField converts a struct field value to its PGINI string representation,
+// using the first of:
+//  1. a custom Marshal<FieldName> method on the struct that declares the field (see fieldAt)
+//  2. the field type's IniMarshaler implementation
+//  3. the field type's encoding.TextMarshaler implementation
+//  4. the pointee for pointers, formatted by steps 2 to 7
+//  5. formatSliceField for slices, formatting each element by steps 2 to 7
+//  6. formatQuantityField for time.Duration, or an integer with a memory unit
+//  7. formatField for primitive types
 //
 // Parameters:
-//   - structValue: the reflect.Value of the dereferenced struct instance
-//   - fieldDef: metadata for the struct field (name, type, tags)
+//   - structValue: the reflect.Value of the dereferenced struct with the custom methods
+//   - field: the struct field's metadata and parsed `ini` tag
 //   - fieldValue: the runtime value of the struct field being marshaled
-func marshalField(structValue reflect.Value, fieldDef reflect.StructField, fieldValue reflect.Value) (string, error) {
-	// Look for a custom marshal method named Marshal<FieldName> on the struct's pointer receiver.
-	methodName := "Marshal" + fieldDef.Name
-	method := structValue.Addr().MethodByName(methodName)
-	if method.IsValid() {
-		return callCustomMarshal(method, fieldDef, fieldValue)
-	}
-	return formatField(fieldValue)
+func marshalField(structValue reflect.Value, field taggedField, fieldValue reflect.Value) (string, error) {
+	// A custom Marshal<FieldName> method, resolved when the struct's plan was compiled.
+	if m := field.marshalMethod; m != nil {
+		if m.err != nil {
+			return "", m.err
+		}
+		return callCustomMarshal(structValue.Addr().Method(m.index), field.def, fieldValue)
+	}
+	return marshalValue(fieldValue, field.tag)
 }
 
-// callCustomMarshal invokes a custom Marshal<FieldName> method and validates
-// its signature: func(s *StructType) Marshal<FieldName>(value *FieldType) (string, error).
+// marshalValue formats v, a field or slice element, using the first of steps 2
+// to 7 of marshalField. tag holds the field's options.
+func marshalValue(v reflect.Value, tag fieldTag) (string, error) {
+	if ok, str, err := marshalInterface(v, tag.name); ok {
+		return str, err
+	}
+	switch {
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			return "", fmt.Errorf("nil %s", v.Type())
+		}
+		return marshalValue(v.Elem(), tag)
+	case v.Kind() == reflect.Slice:
+		return formatSliceField(v, tag)
+	case v.Type() == durationType || tag.unit != "":
+		return formatQuantityField(v, tag.unit)
+	}
+	return formatField(v)
+}
+
+// marshalInterface encodes fieldValue through its IniMarshaler or
+// encoding.TextMarshaler implementation, preferring IniMarshaler. A nil
+// pointer is an error. It reports whether the field type, or a
+// pointer to it, implements either interface.
+func marshalInterface(fieldValue reflect.Value, key string) (bool, string, error) {
+	var target reflect.Value // the value on which to call the method
+	switch {
+	case implementsMarshaler(fieldValue.Type()):
+		target = fieldValue
+	case fieldValue.CanAddr() && implementsMarshaler(reflect.PointerTo(fieldValue.Type())):
+		target = fieldValue.Addr()
+	default:
+		return false, "", nil
+	}
+	if target.Kind() == reflect.Pointer && target.IsNil() {
+		return true, "", fmt.Errorf("nil %s", target.Type())
+	}
+
+	switch m := target.Interface().(type) {
+	case IniMarshaler:
+		p := &Param{Name: key}
+		if err := m.MarshalIniParam(p); err != nil {
+			return true, "", err
+		}
+		return true, p.Value, nil
+	case encoding.TextMarshaler:
+		text, err := m.MarshalText()
+		return true, string(text), err
+	}
+	return false, "", nil
+}
+
+// implementsMarshaler reports whether t implements IniMarshaler or
+// encoding.TextMarshaler.
+func implementsMarshaler(t reflect.Type) bool {
+	return t.Implements(reflect.TypeFor[IniMarshaler]()) ||
+		t.Implements(reflect.TypeFor[encoding.TextMarshaler]())
+}
+
+// checkMarshalMethod validates the signature of a custom Marshal<FieldName>
+// method: func(s *StructType) Marshal<FieldName>(value *FieldType) (string, error).
 //
 // Parameters:
-//   - method: the reflected method value (already resolved via MethodByName)
+//   - methodSig: the method's function signature, without the receiver
 //   - fieldDef: metadata for the struct field (used to derive expected parameter type)
-//   - fieldValue: the runtime value of the struct field to pass to the method
-func callCustomMarshal(method reflect.Value, fieldDef reflect.StructField, fieldValue reflect.Value) (string, error) {
-	methodSig := method.Type() // the method's function signature
-
+func checkMarshalMethod(methodSig reflect.Type, fieldDef reflect.StructField) error {
 	// Validate the method signature: exactly 1 input, 2 outputs (string, error).
 	if methodSig.NumIn() != 1 {
-		return "", fmt.Errorf("Marshal%s: expected 1 parameter, got %d", fieldDef.Name, methodSig.NumIn())
+		return fmt.Errorf("Marshal%s: expected 1 parameter, got %d", fieldDef.Name, methodSig.NumIn())
 	}
 	if methodSig.NumOut() != 2 {
-		return "", fmt.Errorf("Marshal%s: expected 2 return values, got %d", fieldDef.Name, methodSig.NumOut())
+		return fmt.Errorf("Marshal%s: expected 2 return values, got %d", fieldDef.Name, methodSig.NumOut())
 	}
 	if methodSig.Out(0) != reflect.TypeFor[string]() {
-		return "", fmt.Errorf("Marshal%s: first return value must be string, got %s", fieldDef.Name, methodSig.Out(0))
+		return fmt.Errorf("Marshal%s: first return value must be string, got %s", fieldDef.Name, methodSig.Out(0))
 	}
 	if !methodSig.Out(1).Implements(reflect.TypeFor[error]()) {
-		return "", fmt.Errorf("Marshal%s: second return value must be error, got %s", fieldDef.Name, methodSig.Out(1))
+		return fmt.Errorf("Marshal%s: second return value must be error, got %s", fieldDef.Name, methodSig.Out(1))
 	}
 
 	// The input parameter must be a pointer to the field's declared type.
 	expectedParamType := reflect.PointerTo(fieldDef.Type)
 	if methodSig.In(0) != expectedParamType {
-		return "", fmt.Errorf("Marshal%s: parameter must be %s, got %s", fieldDef.Name, expectedParamType, methodSig.In(0))
+		return fmt.Errorf("Marshal%s: parameter must be %s, got %s", fieldDef.Name, expectedParamType, methodSig.In(0))
 	}
+	return nil
+}
 
+// callCustomMarshal invokes a custom Marshal<FieldName> method whose signature
+// checkMarshalMethod has validated.
+//
+// Parameters:
+//   - method: the reflected method value, bound to the struct's pointer
+//   - fieldDef: metadata for the struct field (used to allocate the parameter)
+//   - fieldValue: the runtime value of the struct field to pass to the method
+func callCustomMarshal(method reflect.Value, fieldDef reflect.StructField, fieldValue reflect.Value) (string, error) {
 	// Allocate a new pointer to the field type and copy the field value into it.
 	fieldPtr := reflect.New(fieldDef.Type)
 	fieldPtr.Elem().Set(fieldValue)
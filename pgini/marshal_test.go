@@ -2,8 +2,14 @@ package pgini
 
 import (
 	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/netip"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 // --- test helper types for marshal ---
@@ -292,6 +298,525 @@ func TestMarshalSection(t *testing.T) {
 	})
 }
 
+// --- MarshalFile tests ---
+
+func TestMarshalFile(t *testing.T) {
+	t.Run("default and named sections", func(t *testing.T) {
+		f, err := NewIniFile(nonExistingPath("test.conf"))
+		if err != nil {
+			t.Fatalf("NewIniFile: %v", err)
+		}
+		v := &fileConfig{
+			Name:     "app",
+			Debug:    true,
+			Database: fileDatabase{Host: "db.internal", Port: 6543},
+		}
+		if err := f.MarshalFile(v); err != nil {
+			t.Fatalf("MarshalFile: %v", err)
+		}
+
+		got, err := f.MarshalIni()
+		if err != nil {
+			t.Fatalf("MarshalIni: %v", err)
+		}
+		want := "name = app\ndebug = true\n\n[database]\nhost = db.internal\nport = 6543\n\n[cache]\nhost = ''\nport = 0\n"
+		if string(got) != want {
+			t.Errorf("MarshalIni mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+		}
+
+		restored := &fileConfig{}
+		if err := f.UnmarshalFile(restored); err != nil {
+			t.Fatalf("UnmarshalFile: %v", err)
+		}
+		if *restored != *v {
+			t.Errorf("round trip = %+v, want %+v", *restored, *v)
+		}
+	})
+
+	t.Run("non-struct section field errors", func(t *testing.T) {
+		f, _ := NewIniFile(nonExistingPath("test.conf"))
+		v := &struct {
+			Database int `ini:"database,section"`
+		}{}
+		if err := f.MarshalFile(v); err == nil || !strings.Contains(err.Error(), "must be a struct") {
+			t.Errorf("expected struct error, got %v", err)
+		}
+	})
+
+	t.Run("non-pointer errors", func(t *testing.T) {
+		f, _ := NewIniFile(nonExistingPath("test.conf"))
+		if err := f.MarshalFile(fileConfig{}); err == nil {
+			t.Error("expected error for non-pointer")
+		}
+	})
+}
+
+func TestMarshalFile_SectionsField(t *testing.T) {
+	f, err := NewIniFile(nonExistingPath("pg_service.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	v := &struct {
+		Databases map[string]*fileDatabase `ini:"db_*,sections"`
+	}{Databases: map[string]*fileDatabase{
+		"db_b": {Host: "b", Port: 2},
+		"db_a": {Host: "a", Port: 1},
+		"db_c": nil,
+	}}
+	if err := f.MarshalFile(v); err != nil {
+		t.Fatalf("MarshalFile: %v", err)
+	}
+
+	got, err := f.MarshalIni()
+	if err != nil {
+		t.Fatalf("MarshalIni: %v", err)
+	}
+	want := "\n[db_a]\nhost = a\nport = 1\n\n[db_b]\nhost = b\nport = 2\n"
+	if string(got) != want {
+		t.Errorf("MarshalIni mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+// --- IniMarshaler and encoding.TextMarshaler tests ---
+
+func TestMarshalSection_Interfaces(t *testing.T) {
+	f, err := NewIniFile(nonExistingPath("test.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	v := &unmarshalInterfaces{
+		Addr:  netip.MustParseAddr("192.168.0.1"),
+		IP:    net.ParseIP("::1"),
+		Level: slog.LevelError,
+		Color: color{name: "ini:red"},
+	}
+	v.Big.SetString("123456789012345678901234567890", 10)
+	if err := f.MarshalSection("", v); err != nil {
+		t.Fatalf("MarshalSection: %v", err)
+	}
+
+	s := f.GetSection("")
+	for key, want := range map[string]string{
+		"addr":  "192.168.0.1",
+		"ip":    "::1",
+		"big":   "123456789012345678901234567890",
+		"level": "ERROR",
+		"color": "red_color",
+	} {
+		got, ok := s.GetValue(key)
+		if !ok || got != want {
+			t.Errorf("%s = %q (found %v), want %q", key, got, ok, want)
+		}
+	}
+
+	if p, ok := s.GetParam("big_ptr"); ok {
+		t.Errorf("nil BigPtr should be skipped, got %v", p)
+	}
+
+	restored := &unmarshalInterfaces{}
+	if err := f.UnmarshalSection("", restored); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if restored.Addr != v.Addr || !restored.IP.Equal(v.IP) || restored.Big.Cmp(&v.Big) != 0 || restored.Level != v.Level {
+		t.Errorf("round trip = %+v, want %+v", restored, v)
+	}
+}
+
+// --- time.Duration tests ---
+
+func TestMarshalSection_Durations(t *testing.T) {
+	f, err := NewIniFile(nonExistingPath("test.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	v := &durations{
+		Checkpoint: 5 * time.Minute,
+		Statement:  1500 * time.Millisecond,
+		Idle:       48 * time.Hour,
+	}
+	if err := f.MarshalSection("", v); err != nil {
+		t.Fatalf("MarshalSection: %v", err)
+	}
+
+	s := f.GetSection("")
+	for key, want := range map[string]string{
+		"checkpoint_timeout": "5min",
+		"statement_timeout":  "1500ms",
+		"idle_timeout":       "2d",
+		"retry":              "0",
+	} {
+		got, ok := s.GetValue(key)
+		if !ok || got != want {
+			t.Errorf("%s = %q (found %v), want %q", key, got, ok, want)
+		}
+	}
+
+	restored := &durations{}
+	if err := f.UnmarshalSection("", restored); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if *restored != *v {
+		t.Errorf("round trip = %+v, want %+v", *restored, *v)
+	}
+}
+
+// --- memory unit tests ---
+
+func TestMarshalSection_MemorySizes(t *testing.T) {
+	f, err := NewIniFile(nonExistingPath("test.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	v := &memorySizes{SharedBuffers: 128 << 20, WorkMem: 1000, MaxWal: 2048, Small: -1, Plain: 16}
+	if err := f.MarshalSection("", v); err != nil {
+		t.Fatalf("MarshalSection: %v", err)
+	}
+
+	s := f.GetSection("")
+	for key, want := range map[string]string{
+		"shared_buffers": "128MB",
+		"work_mem":       "1000kB",
+		"max_wal_size":   "2GB",
+		"small":          "-1kB",
+		"plain":          "16",
+	} {
+		got, ok := s.GetValue(key)
+		if !ok || got != want {
+			t.Errorf("%s = %q (found %v), want %q", key, got, ok, want)
+		}
+	}
+
+	restored := &memorySizes{}
+	if err := f.UnmarshalSection("", restored); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if *restored != *v {
+		t.Errorf("round trip = %+v, want %+v", *restored, *v)
+	}
+}
+
+// --- slice tests ---
+
+func TestMarshalSection_Slices(t *testing.T) {
+	f, err := NewIniFile(nonExistingPath("test.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	v := &lists{
+		Hosts:    []string{"db1", "db 2", "a, b"},
+		Ports:    []int{5432, 6432},
+		Paths:    []string{"/usr/bin", "/bin"},
+		Timeouts: []time.Duration{5 * time.Second, 90 * time.Minute},
+		Addrs:    []netip.Addr{netip.MustParseAddr("::1")},
+		Allow:    []string{"a", "b"},
+	}
+	if err := f.MarshalSection("", v); err != nil {
+		t.Fatalf("MarshalSection: %v", err)
+	}
+
+	out, err := f.MarshalIni()
+	if err != nil {
+		t.Fatalf("MarshalIni: %v", err)
+	}
+	want := strings.Join([]string{
+		"hosts = 'db1, db 2, \"a, b\"'",
+		"ports = '5432, 6432'",
+		"paths = /usr/bin:/bin",
+		"timeouts = '5s, 90min'",
+		"addrs = ::1",
+		"allow = 'a, b'",
+		"empty = ''",
+		"",
+	}, "\n")
+	if string(out) != want {
+		t.Errorf("MarshalIni mismatch\n--- got ---\n%s\n--- want ---\n%s", out, want)
+	}
+
+	parsed, err := ParseString("test.conf", string(out))
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	restored := &lists{}
+	if err := parsed.UnmarshalSection("", restored); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	v.Empty = []string{}
+	if !reflect.DeepEqual(*restored, *v) {
+		t.Errorf("round trip = %+v, want %+v", *restored, *v)
+	}
+}
+
+// --- pointer tests ---
+
+func TestMarshalSection_Pointers(t *testing.T) {
+	f, err := NewIniFile(nonExistingPath("test.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	port, enabled, timeout := 0, false, 90*time.Second
+	v := &pointers{Port: &port, Enabled: &enabled, Timeout: &timeout}
+	if err := f.MarshalSection("", v); err != nil {
+		t.Fatalf("MarshalSection: %v", err)
+	}
+
+	out, err := f.MarshalIni()
+	if err != nil {
+		t.Fatalf("MarshalIni: %v", err)
+	}
+	want := "port = 0\nenabled = false\ntimeout = 90s\n"
+	if string(out) != want {
+		t.Errorf("MarshalIni = %q, want %q: nil pointers are skipped", out, want)
+	}
+}
+
+func TestMarshalSection_NilSliceElement(t *testing.T) {
+	type withPointers struct {
+		Ports []*int `ini:"ports"`
+	}
+	f, err := NewIniFile(nonExistingPath("test.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	port := 5432
+	err = f.MarshalSection("", &withPointers{Ports: []*int{&port, nil}})
+	if err == nil || !strings.Contains(err.Error(), "field Ports: element 1: nil *int") {
+		t.Errorf("expected nil element error, got %v", err)
+	}
+}
+
+// --- Sample tests ---
+
+func TestSample(t *testing.T) {
+	type sampleDatabase struct {
+		Host string `ini:"host" default:"localhost"`
+		Port int    `ini:"port" default:"5432"`
+	}
+	type sampleConfig struct {
+		Name     string         `ini:"name" default:"my app"`
+		Timeout  time.Duration  `ini:"timeout,unit=s" default:"30"`
+		Debug    bool           `ini:"debug"`
+		Optional *int           `ini:"optional"`
+		Database sampleDatabase `ini:"database,section"`
+	}
+
+	out, err := Sample[sampleConfig]()
+	if err != nil {
+		t.Fatalf("Sample: %v", err)
+	}
+	want := strings.Join([]string{
+		"#name = 'my app'",
+		"#timeout = 30s",
+		"#debug = false",
+		"",
+		"[database]",
+		"#host = localhost",
+		"#port = 5432",
+		"",
+	}, "\n")
+	if string(out) != want {
+		t.Errorf("Sample mismatch\n--- got ---\n%s\n--- want ---\n%s", out, want)
+	}
+
+	// The sample loads as if empty, so decoding it yields the defaults.
+	f, err := ParseBytes("sample.conf", out)
+	if err != nil {
+		t.Fatalf("ParseBytes: %v", err)
+	}
+	for _, s := range f.Sections() {
+		for _, p := range s.Params() {
+			t.Errorf("sample should set no params, got %v", p)
+		}
+	}
+}
+
+// --- embedded and inline struct tests ---
+
+func TestMarshalFile_Inline(t *testing.T) {
+	v := &inlineConfig{
+		Database: inlineDatabase{
+			inlineTLS: inlineTLS{CertFile: "/etc/db.pem", Port: 5432},
+			Host:      "db",
+		},
+		Cache: inlineCache{TLS: inlineTLS{Mode: "verify-full"}},
+	}
+	f, err := NewIniFile(nonExistingPath("inline.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	if err := f.MarshalFile(v); err != nil {
+		t.Fatalf("MarshalFile: %v", err)
+	}
+
+	got, err := f.MarshalIni()
+	if err != nil {
+		t.Fatalf("MarshalIni: %v", err)
+	}
+	want := strings.Join([]string{
+		"",
+		"[database]",
+		"cert_file = /etc/db.pem",
+		"mode = ''",
+		"port = 5432",
+		"host = db",
+		"",
+		"[cache]",
+		"host = ''",
+		"tls_cert_file = ''",
+		"tls_mode = verify-full",
+		"tls_port = 0",
+		"",
+	}, "\n")
+	if string(got) != want {
+		t.Errorf("MarshalIni mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+
+	back, err := ParseString("inline.conf", string(got))
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v2 := &inlineConfig{}
+	if err := back.UnmarshalFile(v2); err != nil {
+		t.Fatalf("UnmarshalFile: %v", err)
+	}
+	if *v2 != *v {
+		t.Errorf("round trip = %+v, want %+v", *v2, *v)
+	}
+}
+
+// --- remain field and map tests ---
+
+func TestMarshalSection_Remain(t *testing.T) {
+	v := &remainPassthrough{
+		Host:    "db",
+		Port:    5432,
+		Options: map[string]string{"sslmode": "require", "application_name": "app"},
+	}
+	f, err := NewIniFile(nonExistingPath("remain.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	if err := f.MarshalSection("", v); err != nil {
+		t.Fatalf("MarshalSection: %v", err)
+	}
+
+	got, err := f.MarshalIni()
+	if err != nil {
+		t.Fatalf("MarshalIni: %v", err)
+	}
+	want := strings.Join([]string{
+		"host = db",
+		"port = 5432",
+		"application_name = app",
+		"sslmode = require",
+		"",
+	}, "\n")
+	if string(got) != want {
+		t.Errorf("MarshalIni mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+
+	back, err := ParseString("remain.conf", string(got))
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v2 := &remainPassthrough{}
+	if err := back.UnmarshalSection("", v2); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if !reflect.DeepEqual(v2, v) {
+		t.Errorf("round trip = %+v, want %+v", *v2, *v)
+	}
+}
+
+func TestMarshalSection_RemainCollision(t *testing.T) {
+	v := &remainPassthrough{Host: "db", Options: map[string]string{"HOST": "other"}}
+	f, err := NewIniFile(nonExistingPath("remain.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	err = f.MarshalSection("", v)
+	if err == nil || !strings.Contains(err.Error(), `field Options: key "HOST" collides with field Host`) {
+		t.Errorf("expected a collision error, got %v", err)
+	}
+}
+
+func TestMarshalSection_Map(t *testing.T) {
+	f, err := NewIniFile(nonExistingPath("labels.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	labels := map[string]string{"tier": "backend", "team": "db", "owner": "ops team"}
+	if err := f.MarshalSection("labels", &labels); err != nil {
+		t.Fatalf("MarshalSection: %v", err)
+	}
+	extra := map[string]any{"replicas": 3, "timeout": 90 * time.Second, "debug": true, "unset": nil}
+	if err := f.MarshalSection("extra", &extra); err != nil {
+		t.Fatalf("MarshalSection: %v", err)
+	}
+
+	got, err := f.MarshalIni()
+	if err != nil {
+		t.Fatalf("MarshalIni: %v", err)
+	}
+	want := strings.Join([]string{
+		"",
+		"[labels]",
+		"owner = 'ops team'",
+		"team = db",
+		"tier = backend",
+		"",
+		"[extra]",
+		"debug = true",
+		"replicas = 3",
+		"timeout = 90s",
+		"",
+	}, "\n")
+	if string(got) != want {
+		t.Errorf("MarshalIni mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestMarshalSection_MapRoundTrip(t *testing.T) {
+	f, err := NewIniFile(nonExistingPath("labels.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	labels := map[string]string{"a": "it's", "b": `c:\d`}
+	if err := f.MarshalSection("labels", &labels); err != nil {
+		t.Fatalf("MarshalSection: %v", err)
+	}
+	out, err := f.MarshalIni()
+	if err != nil {
+		t.Fatalf("MarshalIni: %v", err)
+	}
+	parsed, err := ParseBytes("labels.conf", out)
+	if err != nil {
+		t.Fatalf("ParseBytes: %v\n%s", err, out)
+	}
+	var got map[string]string
+	if err := parsed.UnmarshalSection("labels", &got); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if !maps.Equal(got, labels) {
+		t.Errorf("labels = %q, want %q", got, labels)
+	}
+}
+
+func TestMarshalSection_MapErrors(t *testing.T) {
+	f, err := NewIniFile(nonExistingPath("labels.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	if err := f.MarshalSection("labels", &map[string]string{"not a key": "x"}); err == nil || !strings.Contains(err.Error(), "invalid parameter key") {
+		t.Errorf("expected an invalid key error, got %v", err)
+	}
+	if err := f.MarshalSection("labels", &map[string]any{"point": struct{ X int }{}}); err == nil || !strings.Contains(err.Error(), `key "point": unsupported field type: struct`) {
+		t.Errorf("expected an unsupported type error, got %v", err)
+	}
+	if err := f.MarshalSection("labels", map[string]string{}); err == nil || !strings.Contains(err.Error(), "pointer to a struct or to a map[string]string") {
+		t.Errorf("expected a target type error, got %v", err)
+	}
+}
+
 // --- formatField tests ---
 
 func TestFormatField(t *testing.T) {
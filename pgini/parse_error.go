@@ -0,0 +1,108 @@
+// Parse errors describe where and why a PGINI file failed to parse. Every
+// error returned by the parser for a problem inside a file is a *ParseError,
+// so callers can inspect it with errors.As instead of matching strings.
+
+package pgini
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorKind classifies a ParseError.
+type ErrorKind int
+
+const (
+	// UnknownError is the zero ErrorKind.
+	UnknownError ErrorKind = iota
+	// UnexpectedCharacter is a character the grammar does not allow at its position.
+	UnexpectedCharacter
+	// UnterminatedQuote is a quoted value or path without a closing quote.
+	UnterminatedQuote
+	// InvalidEscape is an unknown or incomplete backslash escape sequence.
+	InvalidEscape
+	// BadSectionHeader is a malformed [section] header.
+	BadSectionHeader
+	// InvalidIdentifier is a key or section name that is not a PGINI identifier.
+	InvalidIdentifier
+	// BadInclude is a malformed or unresolvable include directive.
+	BadInclude
+	// MissingInclude is an include or include_dir target that could not be read.
+	MissingInclude
+	// CircularInclude is a file included more than maxVisitCount times.
+	CircularInclude
+)
+
+// String returns a human-readable name for the ErrorKind.
+func (k ErrorKind) String() string {
+	switch k {
+	case UnexpectedCharacter:
+		return "unexpected character"
+	case UnterminatedQuote:
+		return "unterminated quote"
+	case InvalidEscape:
+		return "invalid escape"
+	case BadSectionHeader:
+		return "bad section header"
+	case InvalidIdentifier:
+		return "invalid identifier"
+	case BadInclude:
+		return "bad include"
+	case MissingInclude:
+		return "missing include"
+	case CircularInclude:
+		return "circular include"
+	default:
+		return "unknown error"
+	}
+}
+
+// ParseError is a problem found at a specific position in a PGINI file.
+type ParseError struct {
+	// Path is the path of the file containing the problem.
+	Path string
+	// Line is the 1-indexed line number.
+	Line int
+	// Column is the 1-indexed byte column within the line.
+	Column int
+	// Kind classifies the problem.
+	Kind ErrorKind
+	// Source is the full text of the offending line.
+	Source string
+	// Msg describes the problem.
+	Msg string
+	// Err is the underlying cause, if any.
+	Err error
+}
+
+// Error formats the error as "path:line:column: message".
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.Path, e.Line, e.Column, e.Msg)
+}
+
+// Unwrap returns the underlying cause, if any.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors lists every ParseError found by a parse in recovery mode.
+// See WithRecovery.
+type ParseErrors []*ParseError
+
+// Error formats each error on its own line.
+func (e ParseErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the individual errors for use with errors.Is and errors.As.
+func (e ParseErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
@@ -0,0 +1,151 @@
+package pgini
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// requireParseError asserts err is a *ParseError and returns it.
+func requireParseError(t *testing.T, err error) *ParseError {
+	t.Helper()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("error %T (%v) is not a *ParseError", err, err)
+	}
+	return perr
+}
+
+// ---------------------------------------------------------------------------
+// ParseError — kind and position for each error file
+// ---------------------------------------------------------------------------
+
+func TestParseError_Files(t *testing.T) {
+	tests := []struct {
+		file     string
+		wantKind ErrorKind
+		wantLine int
+		wantCol  int
+	}{
+		{"errors/unterminated_quote.conf", UnterminatedQuote, 1, 20},
+		{"errors/invalid_escape.conf", InvalidEscape, 1, 8},
+		{"errors/unterminated_escape.conf", InvalidEscape, 1, 11},
+		{"errors/section_unclosed.conf", BadSectionHeader, 1, 10},
+		{"errors/section_empty.conf", BadSectionHeader, 1, 2},
+		{"errors/key_digit_start.conf", UnexpectedCharacter, 1, 1},
+		{"errors/trailing_garbage_after_value.conf", UnexpectedCharacter, 1, 13},
+		{"errors/include_no_quote.conf", BadInclude, 1, 9},
+		{"errors/include_missing_file.conf", MissingInclude, 1, 1},
+		{"errors/include_dir_not_found.conf", MissingInclude, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			_, err := Parse(unitPath(tt.file))
+			perr := requireParseError(t, err)
+			if perr.Kind != tt.wantKind {
+				t.Errorf("Kind = %v, want %v", perr.Kind, tt.wantKind)
+			}
+			if perr.Path != unitPath(tt.file) {
+				t.Errorf("Path = %q, want %q", perr.Path, unitPath(tt.file))
+			}
+			if perr.Line != tt.wantLine {
+				t.Errorf("Line = %d, want %d", perr.Line, tt.wantLine)
+			}
+			if perr.Column != tt.wantCol {
+				t.Errorf("Column = %d, want %d", perr.Column, tt.wantCol)
+			}
+			if perr.Source == "" {
+				t.Error("Source should hold the offending line")
+			}
+		})
+	}
+}
+
+func TestParseError_SourceAndPosition(t *testing.T) {
+	_, err := ParseString("app.conf", "host = ok\n\n[db]\nport = 5432 extra\n")
+	perr := requireParseError(t, err)
+	if perr.Line != 4 || perr.Column != 13 {
+		t.Errorf("position = %d:%d, want 4:13", perr.Line, perr.Column)
+	}
+	if perr.Source != "port = 5432 extra" {
+		t.Errorf("Source = %q, want %q", perr.Source, "port = 5432 extra")
+	}
+	if want := "app.conf:4:13: unexpected character 'e' after value"; perr.Error() != want {
+		t.Errorf("Error() = %q, want %q", perr.Error(), want)
+	}
+}
+
+func TestParseError_MissingIncludeUnwraps(t *testing.T) {
+	_, err := Parse(unitPath("errors/include_missing_file.conf"))
+	perr := requireParseError(t, err)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("errors.Is(err, fs.ErrNotExist) = false for %v", err)
+	}
+	if perr.Unwrap() == nil {
+		t.Error("Unwrap() should return the underlying read error")
+	}
+}
+
+func TestParseError_CircularInclude(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.conf":  {Data: []byte("a = 1\n  include 'child.conf'\n")},
+		"child.conf": {Data: []byte("b = 2\n\ninclude 'main.conf'\n")},
+	}
+	_, err := ParseFS(fsys, "main.conf")
+	perr := requireParseError(t, err)
+	if perr.Kind != CircularInclude {
+		t.Fatalf("Kind = %v, want %v", perr.Kind, CircularInclude)
+	}
+	// The innermost directive that would exceed the limit is reported. Every
+	// include of main.conf happens on line 3, column 1 of child.conf.
+	if perr.Path != "child.conf" || perr.Line != 3 || perr.Column != 1 {
+		t.Errorf("position = %s:%d:%d, want child.conf:3:1", perr.Path, perr.Line, perr.Column)
+	}
+	if perr.Source != "include 'main.conf'" {
+		t.Errorf("Source = %q, want %q", perr.Source, "include 'main.conf'")
+	}
+}
+
+func TestParseError_CircularIncludeFromCursor(t *testing.T) {
+	p := writeTemp(t, t.TempDir(), "root.conf", "key = val\n")
+	rc, err := NewRootCursor(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < maxVisitCount; i++ {
+		if err := rc.AddInclude(p); err != nil {
+			t.Fatalf("AddInclude #%d: %v", i+1, err)
+		}
+	}
+	perr := requireParseError(t, rc.AddInclude(p))
+	if perr.Kind != CircularInclude {
+		t.Errorf("Kind = %v, want %v", perr.Kind, CircularInclude)
+	}
+	// Before any line is read, the position clamps to 1:1.
+	if perr.Line != 1 || perr.Column != 1 {
+		t.Errorf("position = %d:%d, want 1:1", perr.Line, perr.Column)
+	}
+}
+
+func TestErrorKind_String(t *testing.T) {
+	kinds := []ErrorKind{
+		UnknownError, UnexpectedCharacter, UnterminatedQuote, InvalidEscape,
+		BadSectionHeader, InvalidIdentifier, BadInclude, MissingInclude, CircularInclude,
+	}
+	seen := make(map[string]bool)
+	for _, k := range kinds {
+		s := k.String()
+		if s == "" || seen[s] {
+			t.Errorf("ErrorKind(%d).String() = %q, want unique non-empty", int(k), s)
+		}
+		seen[s] = true
+	}
+	if got := ErrorKind(99).String(); !strings.Contains(got, "unknown") {
+		t.Errorf("ErrorKind(99).String() = %q, want unknown", got)
+	}
+}
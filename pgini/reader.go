@@ -9,8 +9,9 @@ package pgini
 import (
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 )
@@ -18,14 +19,34 @@ import (
 // Load parses the PGINI file at filePath and unmarshals the named section into
 // a new instance of T. T must be a struct with `ini:"KEY"` field tags.
 // Use an empty string for section to read the default (unnamed) section.
-func Load[T any](filePath string, section string) (*T, error) {
-	f, err := Parse(filePath)
+// opts configure decoding, as for IniFile.UnmarshalSection, and parsing,
+// through WithParseOptions.
+func Load[T any](filePath string, section string, opts ...UnmarshalOption) (*T, error) {
+	f, err := Parse(filePath, newUnmarshalOptions(opts).parseOpts...)
 	if err != nil {
 		return nil, err
 	}
 
 	var t T
-	if err := f.UnmarshalSection(section, &t); err != nil {
+	if err := f.UnmarshalSection(section, &t, opts...); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// LoadFile parses the PGINI file at filePath and unmarshals the whole file
+// into a new instance of T, as by IniFile.UnmarshalFile. T must be a struct
+// with `ini:"KEY"` field tags; fields tagged `ini:"NAME,section"` are decoded
+// from the [NAME] section. opts configure decoding, as for UnmarshalFile, and
+// parsing, through WithParseOptions.
+func LoadFile[T any](filePath string, opts ...UnmarshalOption) (*T, error) {
+	f, err := Parse(filePath, newUnmarshalOptions(opts).parseOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var t T
+	if err := f.UnmarshalFile(&t, opts...); err != nil {
 		return nil, err
 	}
 	return &t, nil
@@ -35,22 +56,159 @@ func Load[T any](filePath string, section string) (*T, error) {
 // into the struct pointed to by structPtr. structPtr must be a pointer to a
 // struct with `ini:"KEY"` field tags.
 // Use an empty string for section to read the default (unnamed) section.
-func LoadInto(filePath string, section string, structPtr any) error {
-	f, err := Parse(filePath)
+// opts configure decoding, as for IniFile.UnmarshalSection, and parsing,
+// through WithParseOptions.
+func LoadInto(filePath string, section string, structPtr any, opts ...UnmarshalOption) error {
+	f, err := Parse(filePath, newUnmarshalOptions(opts).parseOpts...)
 	if err != nil {
 		return err
 	}
-	return f.UnmarshalSection(section, structPtr)
+	return f.UnmarshalSection(section, structPtr, opts...)
+}
+
+// LoadReader parses PGINI contents read from r and unmarshals the named section
+// into a new instance of T. The name identifies the source in errors. See
+// ParseReader for how include directives are handled; pass WithBaseDir through
+// WithParseOptions to allow them. opts configure decoding, as for
+// IniFile.UnmarshalSection, and parsing, through WithParseOptions.
+func LoadReader[T any](name string, r io.Reader, section string, opts ...UnmarshalOption) (*T, error) {
+	f, err := ParseReader(name, r, newUnmarshalOptions(opts).parseOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var t T
+	if err := f.UnmarshalSection(section, &t, opts...); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ParseOption configures optional parser behavior.
+type ParseOption func(*parseOptions)
+
+// parseOptions holds the settings applied by ParseOption functions.
+type parseOptions struct {
+	// baseDir resolves relative include paths for in-memory sources.
+	baseDir string
+	// recovering collects line errors instead of stopping at the first.
+	recovering bool
+	// skipIncludes checks include directives for syntax without following them.
+	skipIncludes bool
+	// missingInclude, when set, receives each include_if_exists target that
+	// does not exist; Lint reports them as warnings.
+	missingInclude func(*ParseError)
+	// doc records the root file's lines; nil when not building a Document.
+	doc *Document
+}
+
+// WithBaseDir sets the directory that include directives in in-memory sources
+// resolve against. Without it, ParseReader, ParseBytes, and ParseString reject
+// include directives.
+func WithBaseDir(dir string) ParseOption {
+	return func(o *parseOptions) {
+		o.baseDir = dir
+	}
+}
+
+// WithRecovery makes the parser skip lines that fail to parse, including
+// lines of included files, and keep going; a file of an include_dir that cannot
+// be included is skipped too. The parse functions then return the
+// partially populated IniFile together with a ParseErrors listing every
+// problem, in the order encountered. Errors that prevent reading the root file
+// are still returned alone, with a nil IniFile.
+func WithRecovery() ParseOption {
+	return func(o *parseOptions) {
+		o.recovering = true
+	}
+}
+
+// newParseOptions applies opts over the default parser settings.
+func newParseOptions(opts []ParseOption) *parseOptions {
+	o := &parseOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
 }
 
 // Parse parses the PGINI file at filePath (and any included files) and returns
 // a populated IniFile.
-func Parse(filePath string) (*IniFile, error) {
+func Parse(filePath string, opts ...ParseOption) (*IniFile, error) {
+	o := newParseOptions(opts)
 	rootCursor, err := NewRootCursor(filePath)
 	if err != nil {
 		return nil, err
 	}
+	return parseRoot(rootCursor, o)
+}
+
+// ParseReader parses PGINI contents read from r and returns a populated
+// IniFile. The name identifies the source in errors and in IniFile.Path.
+// Include directives resolve relative to the directory given by WithBaseDir,
+// and are rejected when no base directory is given.
+func ParseReader(name string, r io.Reader, opts ...ParseOption) (*IniFile, error) {
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", name, err)
+	}
+	return ParseBytes(name, contents, opts...)
+}
+
+// ParseBytes parses PGINI contents and returns a populated IniFile.
+// See ParseReader for how name and include directives are handled.
+func ParseBytes(name string, contents []byte, opts ...ParseOption) (*IniFile, error) {
+	o := newParseOptions(opts)
+	rootCursor, err := NewRootCursorBytes(name, contents, o.baseDir)
+	if err != nil {
+		return nil, err
+	}
+	return parseRoot(rootCursor, o)
+}
+
+// ParseString parses PGINI contents and returns a populated IniFile.
+// See ParseReader for how name and include directives are handled.
+func ParseString(name string, contents string, opts ...ParseOption) (*IniFile, error) {
+	return ParseBytes(name, []byte(contents), opts...)
+}
+
+// ParseFS parses the PGINI file at name within fsys (and any included files)
+// and returns a populated IniFile. Include directives resolve within fsys and
+// can never reach files outside of it.
+func ParseFS(fsys fs.FS, name string, opts ...ParseOption) (*IniFile, error) {
+	o := newParseOptions(opts)
+	rootCursor, err := NewRootCursorFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return parseRoot(rootCursor, o)
+}
+
+// LoadFS parses the PGINI file at name within fsys and unmarshals the named
+// section into a new instance of T. See ParseFS for how includes resolve.
+// opts configure decoding, as for IniFile.UnmarshalSection, and parsing,
+// through WithParseOptions.
+func LoadFS[T any](fsys fs.FS, name string, section string, opts ...UnmarshalOption) (*T, error) {
+	f, err := ParseFS(fsys, name, newUnmarshalOptions(opts).parseOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var t T
+	if err := f.UnmarshalSection(section, &t, opts...); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
 
+// parseRoot parses the root file of rootCursor (and any included files) and
+// returns the populated IniFile. In recovery mode, it returns the partially
+// populated IniFile together with a ParseErrors when any line failed.
+func parseRoot(rootCursor *RootCursor, o *parseOptions) (*IniFile, error) {
+	rootCursor.recovering = o.recovering
+	rootCursor.File.fsys = rootCursor.fsys
 	cursor := rootCursor.NextInclude()
 	if cursor == nil {
 		return rootCursor.File, nil
@@ -58,10 +216,13 @@ func Parse(filePath string) (*IniFile, error) {
 
 	// Start parsing into the default section.
 	currentSection := rootCursor.File.GetSection("")
-	if err := parseCursor(rootCursor, cursor, &currentSection); err != nil {
+	if err := parseCursor(rootCursor, cursor, &currentSection, o); err != nil {
 		return nil, err
 	}
 
+	if len(rootCursor.errs) > 0 {
+		return rootCursor.File, ParseErrors(rootCursor.errs)
+	}
 	return rootCursor.File, nil
 }
 
@@ -74,57 +235,75 @@ func Parse(filePath string) (*IniFile, error) {
 //   - rootCursor: owns the IniFile and tracks visited files for circular detection
 //   - cursor: the FileCursor for the current file being parsed
 //   - currentSection: pointer to the active section; updated when [section] headers are encountered
-func parseCursor(rootCursor *RootCursor, cursor *FileCursor, currentSection **Section) error {
+//   - o: the parse options, which also carry Lint and Document state
+func parseCursor(rootCursor *RootCursor, cursor *FileCursor, currentSection **Section, o *parseOptions) error {
 	for line, ok := cursor.NextLine(); ok; line, ok = cursor.NextLine() {
-		pos := skipWSP(line, 0)
-
-		// blank line
-		if pos >= len(line) {
-			continue
+		err := parseLine(rootCursor, cursor, currentSection, line, o)
+		recordDocLine(o.doc, cursor, line, *currentSection, err)
+		if err != nil {
+			if err := recoverParseErr(rootCursor, err); err != nil {
+				return err
+			}
 		}
+	}
+	return nil
+}
 
-		ch := rune(line[pos])
+// parseLine parses the cursor's current line, which holds the text line.
+func parseLine(rootCursor *RootCursor, cursor *FileCursor, currentSection **Section, line string, o *parseOptions) error {
+	pos := skipWSP(line, 0)
 
-		// comment line
-		if isComment(ch) {
-			continue
-		}
+	// blank line
+	if pos >= len(line) {
+		return nil
+	}
 
-		// section header
-		if ch == '[' {
-			section, err := parseSectionHeader(cursor, line, pos)
-			if err != nil {
-				return err
-			}
-			added, err := rootCursor.File.AddSection(section)
-			if err != nil {
-				return parseErrf(cursor, pos, "%s", err)
-			}
-			*currentSection = added
-			continue
-		}
+	ch := rune(line[pos])
 
-		// identifier: parameter or include directive
-		if isLetter(ch) {
-			ident, newPos := scanIdentifier(line, pos)
-			directive := strings.ToLower(ident)
+	// comment line
+	if isComment(ch) {
+		return nil
+	}
 
-			if directive == "include" || directive == "include_if_exists" || directive == "include_dir" {
-				if err := parseInclude(rootCursor, cursor, currentSection, line, newPos, directive); err != nil {
-					return err
-				}
-				continue
-			}
+	// section header
+	if ch == '[' {
+		section, err := parseSectionHeader(cursor, line, pos)
+		if err != nil {
+			return err
+		}
+		added, err := rootCursor.File.AddSection(section)
+		if err != nil {
+			return parseErrf(cursor, pos, BadSectionHeader, "%s", err)
+		}
+		added.Origins = append(added.Origins, cursorLocation(cursor, pos))
+		*currentSection = added
+		return nil
+	}
 
-			// parameter
-			if err := parseParameter(cursor, *currentSection, line, ident, pos, newPos); err != nil {
-				return err
-			}
-			continue
+	// identifier: parameter or include directive
+	if isLetter(ch) {
+		ident, newPos := scanIdentifier(line, pos)
+		directive := strings.ToLower(ident)
+
+		if directive == "include" || directive == "include_if_exists" || directive == "include_dir" {
+			return parseInclude(rootCursor, cursor, currentSection, line, newPos, directive, o)
 		}
 
-		return parseErrf(cursor, pos, "unexpected character %q", ch)
+		// parameter
+		return parseParameter(cursor, *currentSection, line, ident, pos, newPos)
 	}
+
+	return parseErrf(cursor, pos, UnexpectedCharacter, "unexpected character %q", ch)
+}
+
+// recoverParseErr records err and returns nil when rootCursor is in recovery
+// mode, so parsing continues with the next line. Otherwise it returns err.
+func recoverParseErr(rootCursor *RootCursor, err error) error {
+	var perr *ParseError
+	if !rootCursor.recovering || !errors.As(err, &perr) {
+		return err
+	}
+	rootCursor.errs = append(rootCursor.errs, perr)
 	return nil
 }
 
@@ -135,25 +314,25 @@ func parseSectionHeader(cursor *FileCursor, line string, pos int) (string, error
 	pos = skipWSP(line, pos)
 
 	if pos >= len(line) || rune(line[pos]) == ']' {
-		return "", parseErrf(cursor, pos, "empty section name")
+		return "", parseErrf(cursor, pos, BadSectionHeader, "empty section name")
 	}
 
 	if !isLetter(rune(line[pos])) {
-		return "", parseErrf(cursor, pos, "invalid section name start %q", rune(line[pos]))
+		return "", parseErrf(cursor, pos, BadSectionHeader, "invalid section name start %q", rune(line[pos]))
 	}
 
 	name, pos := scanIdentifier(line, pos)
 
 	pos = skipWSP(line, pos)
 	if pos >= len(line) || rune(line[pos]) != ']' {
-		return "", parseErrf(cursor, pos, "expected ']' after section name %q", name)
+		return "", parseErrf(cursor, pos, BadSectionHeader, "expected ']' after section name %q", name)
 	}
 	pos++ // skip ']'
 
 	// After ']', only whitespace and an optional comment are allowed.
 	pos = skipWSP(line, pos)
 	if pos < len(line) && !isComment(rune(line[pos])) {
-		return "", parseErrf(cursor, pos, "unexpected character %q after section header", rune(line[pos]))
+		return "", parseErrf(cursor, pos, BadSectionHeader, "unexpected character %q after section header", rune(line[pos]))
 	}
 
 	return name, nil
@@ -188,27 +367,29 @@ func parseParameter(cursor *FileCursor, section *Section, line string, key strin
 	// After value, only whitespace and optional comment allowed.
 	pos = skipWSP(line, pos)
 	if pos < len(line) && !isComment(rune(line[pos])) {
-		return parseErrf(cursor, pos, "unexpected character %q after value", rune(line[pos]))
+		return parseErrf(cursor, pos, UnexpectedCharacter, "unexpected character %q after value", rune(line[pos]))
 	}
 
-	if _, err := section.SetParam(key, value); err != nil {
-		return parseErrf(cursor, keyPos, "%s", err)
+	if _, err := section.setParamAt(key, value, cursorLocation(cursor, keyPos)); err != nil {
+		return parseErrf(cursor, keyPos, InvalidIdentifier, "%s", err)
 	}
 	return nil
 }
 
 // parseInclude handles include, include_if_exists, and include_dir directives.
 // pos is the byte position after the directive identifier.
-func parseInclude(rootCursor *RootCursor, cursor *FileCursor, currentSection **Section, line string, pos int, directive string) error {
+func parseInclude(rootCursor *RootCursor, cursor *FileCursor, currentSection **Section, line string, pos int, directive string, o *parseOptions) error {
+	directivePos := pos - len(directive)
+
 	// Require at least one whitespace after the directive name.
 	if pos >= len(line) || !isWSP(rune(line[pos])) {
-		return parseErrf(cursor, pos, "%s requires a quoted path argument", directive)
+		return parseErrf(cursor, pos, BadInclude, "%s requires a quoted path argument", directive)
 	}
 	pos = skipWSP(line, pos)
 
 	// Parse the quoted path.
 	if pos >= len(line) || line[pos] != '\'' {
-		return parseErrf(cursor, pos, "%s requires a single-quoted path", directive)
+		return parseErrf(cursor, pos, BadInclude, "%s requires a single-quoted path", directive)
 	}
 	quotedPath, newPos, err := scanQuotedPath(cursor, line, pos)
 	if err != nil {
@@ -219,54 +400,79 @@ func parseInclude(rootCursor *RootCursor, cursor *FileCursor, currentSection **S
 	// After quoted path, only whitespace and optional comment allowed.
 	pos = skipWSP(line, pos)
 	if pos < len(line) && !isComment(rune(line[pos])) {
-		return parseErrf(cursor, pos, "unexpected character %q after %s path", rune(line[pos]), directive)
+		return parseErrf(cursor, pos, BadInclude, "unexpected character %q after %s path", rune(line[pos]), directive)
 	}
 
 	if quotedPath == "" {
-		return parseErrf(cursor, pos, "%s path must not be empty", directive)
+		return parseErrf(cursor, pos, BadInclude, "%s path must not be empty", directive)
+	}
+	if o.skipIncludes {
+		return nil
 	}
 
 	// Resolve relative paths against the current file's directory.
-	resolvedPath := quotedPath
-	if !filepath.IsAbs(resolvedPath) {
-		resolvedPath = filepath.Join(filepath.Dir(cursor.Path), resolvedPath)
+	resolvedPath, err := rootCursor.resolvePath(quotedPath)
+	if err != nil {
+		return parseErrf(cursor, directivePos, BadInclude, "%w", err)
 	}
 
 	switch directive {
 	case "include":
-		return processIncludeFile(rootCursor, cursor, currentSection, resolvedPath, true)
+		return processIncludeFile(rootCursor, cursor, currentSection, resolvedPath, directivePos, true, o)
 	case "include_if_exists":
-		return processIncludeFile(rootCursor, cursor, currentSection, resolvedPath, false)
+		return processIncludeFile(rootCursor, cursor, currentSection, resolvedPath, directivePos, false, o)
 	case "include_dir":
-		return processIncludeDir(rootCursor, cursor, currentSection, resolvedPath)
+		return processIncludeDir(rootCursor, cursor, currentSection, resolvedPath, directivePos, o)
 	}
 	return nil
 }
 
-// processIncludeFile adds a single include file to the root cursor and
-// immediately parses it. If required is false, missing files are silently skipped.
-func processIncludeFile(rootCursor *RootCursor, cursor *FileCursor, currentSection **Section, filePath string, required bool) error {
-	err := rootCursor.AddInclude(filePath)
+// processIncludeFile adds a single resolved include file to the root cursor
+// and immediately parses it. If required is false, missing files are skipped and
+// passed to o.missingInclude, if set.
+// col is the byte position of the include directive, used for error reporting.
+func processIncludeFile(rootCursor *RootCursor, cursor *FileCursor, currentSection **Section, filePath string, col int, required bool, o *parseOptions) error {
+	err := rootCursor.pushInclude(filePath)
 	if err != nil {
-		if !required && os.IsNotExist(unwrapRootErr(err)) {
-			return nil
+		if os.IsNotExist(unwrapRootErr(err)) {
+			if !required {
+				if o.missingInclude != nil {
+					o.missingInclude(parseErrf(cursor, col, MissingInclude, "include_if_exists target %q does not exist", filePath).(*ParseError))
+				}
+				return nil
+			}
+			return parseErrf(cursor, col, MissingInclude, "%w", err)
+		}
+		var circular *ParseError
+		if errors.As(err, &circular) {
+			return parseErrf(cursor, col, circular.Kind, "%s", circular.Msg)
 		}
-		return parseErrf(cursor, 0, "%s", err)
+		return parseErrf(cursor, col, BadInclude, "%w", err)
 	}
 
 	includeCursor := rootCursor.NextInclude()
 	if includeCursor == nil {
 		return nil
 	}
-	return parseCursor(rootCursor, includeCursor, currentSection)
+	err = parseCursor(rootCursor, includeCursor, currentSection, o)
+
+	// Restore the including file as current so later directives resolve
+	// against its directory.
+	rootCursor.current = cursor
+	return err
 }
 
-// processIncludeDir reads all .conf files from a directory (skipping dotfiles),
+// processIncludeDir reads all .conf files from a resolved directory (skipping dotfiles),
 // sorts them in ascending order, and includes each one.
-func processIncludeDir(rootCursor *RootCursor, cursor *FileCursor, currentSection **Section, dirPath string) error {
-	entries, err := os.ReadDir(dirPath)
+// col is the byte position of the include_dir directive, used for error reporting.
+func processIncludeDir(rootCursor *RootCursor, cursor *FileCursor, currentSection **Section, dirPath string, col int, o *parseOptions) error {
+	entries, err := rootCursor.readDir(dirPath)
 	if err != nil {
-		return parseErrf(cursor, 0, "include_dir %q: %s", dirPath, err)
+		kind := BadInclude
+		if os.IsNotExist(unwrapRootErr(err)) {
+			kind = MissingInclude
+		}
+		return parseErrf(cursor, col, kind, "include_dir %q: %w", dirPath, err)
 	}
 
 	// Collect .conf files, skip dotfiles.
@@ -276,13 +482,16 @@ func processIncludeDir(rootCursor *RootCursor, cursor *FileCursor, currentSectio
 		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".conf") {
 			continue
 		}
-		confFiles = append(confFiles, filepath.Join(dirPath, name))
+		confFiles = append(confFiles, rootCursor.joinPath(dirPath, name))
 	}
 	sort.Strings(confFiles)
 
 	for _, confPath := range confFiles {
-		if err := processIncludeFile(rootCursor, cursor, currentSection, confPath, true); err != nil {
-			return err
+		if err := processIncludeFile(rootCursor, cursor, currentSection, confPath, col, true, o); err != nil {
+			// In recovery mode, record the error and go on with the next file.
+			if err := recoverParseErr(rootCursor, err); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -360,7 +569,7 @@ func scanIdentifier(line string, pos int) (string, int) {
 // Returns the de-escaped value, the position after the closing quote, and any error.
 func scanQuotedValue(cursor *FileCursor, line string, pos int) (string, int, error) {
 	if pos >= len(line) || line[pos] != '\'' {
-		return "", pos, parseErrf(cursor, pos, "expected opening single quote")
+		return "", pos, parseErrf(cursor, pos, UnexpectedCharacter, "expected opening single quote")
 	}
 	pos++ // skip opening quote
 
@@ -384,7 +593,7 @@ func scanQuotedValue(cursor *FileCursor, line string, pos int) (string, int, err
 		// Backslash escape sequence
 		if ch == '\\' {
 			if pos+1 >= len(line) {
-				return "", pos, parseErrf(cursor, pos, "unterminated escape sequence at end of line")
+				return "", pos, parseErrf(cursor, pos, InvalidEscape, "unterminated escape sequence at end of line")
 			}
 			pos++
 			escaped := line[pos]
@@ -415,7 +624,7 @@ func scanQuotedValue(cursor *FileCursor, line string, pos int) (string, int, err
 				b.WriteByte(octVal)
 				continue // pos already advanced past the octal digits
 			default:
-				return "", pos - 1, parseErrf(cursor, pos-1, "invalid escape sequence '\\%c'", escaped)
+				return "", pos - 1, parseErrf(cursor, pos-1, InvalidEscape, "invalid escape sequence '\\%c'", escaped)
 			}
 			pos++
 			continue
@@ -427,7 +636,7 @@ func scanQuotedValue(cursor *FileCursor, line string, pos int) (string, int, err
 	}
 
 	// Reached end of line without closing quote.
-	return "", pos, parseErrf(cursor, pos, "unterminated quoted value")
+	return "", pos, parseErrf(cursor, pos, UnterminatedQuote, "unterminated quoted value")
 }
 
 // scanUnquotedValue extracts an unquoted PGINI value: safe-char+.
@@ -447,7 +656,7 @@ func scanUnquotedValue(line string, pos int) (string, int) {
 // pos must point at the opening single quote.
 func scanQuotedPath(cursor *FileCursor, line string, pos int) (string, int, error) {
 	if pos >= len(line) || line[pos] != '\'' {
-		return "", pos, parseErrf(cursor, pos, "expected opening single quote for path")
+		return "", pos, parseErrf(cursor, pos, BadInclude, "expected opening single quote for path")
 	}
 	pos++ // skip opening quote
 
@@ -461,17 +670,33 @@ func scanQuotedPath(cursor *FileCursor, line string, pos int) (string, int, erro
 		}
 		// Reject control characters per the grammar.
 		if ch <= 0x1F || ch == 0x7F {
-			return "", pos, parseErrf(cursor, pos, "invalid control character in path at position %d", pos)
+			return "", pos, parseErrf(cursor, pos, BadInclude, "invalid control character in path at position %d", pos)
 		}
 		pos++
 	}
 
-	return "", pos, parseErrf(cursor, pos, "unterminated quoted path")
+	return "", pos, parseErrf(cursor, pos, UnterminatedQuote, "unterminated quoted path")
 }
 
-// parseErrf formats a parse error with file path, line number, and column.
-// Line and column are 1-indexed in the output.
-func parseErrf(cursor *FileCursor, col int, format string, args ...any) error {
-	msg := fmt.Sprintf(format, args...)
-	return fmt.Errorf("%s:%d:%d: %s", cursor.Path, cursor.lineOffset+1, col+1, msg)
+// cursorLocation returns the Location of the 0-indexed byte column col on the
+// cursor's current line.
+func cursorLocation(cursor *FileCursor, col int) Location {
+	return Location{Path: cursor.Path, Line: cursor.lineOffset + 1, Column: col + 1}
+}
+
+// parseErrf returns a *ParseError of the given kind at the cursor's current
+// line and the 0-indexed byte column col. Line and column are 1-indexed in the
+// result. A %w verb in format sets the error's underlying cause.
+func parseErrf(cursor *FileCursor, col int, kind ErrorKind, format string, args ...any) error {
+	wrapped := fmt.Errorf(format, args...)
+	source, _ := cursor.GetLine()
+	return &ParseError{
+		Path:   cursor.Path,
+		Line:   cursor.lineOffset + 1,
+		Column: col + 1,
+		Kind:   kind,
+		Source: source,
+		Msg:    wrapped.Error(),
+		Err:    errors.Unwrap(wrapped),
+	}
 }
@@ -1,9 +1,13 @@
 package pgini
 
 import (
+	"errors"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 // unitsDir is the base path for unit test data files.
@@ -974,3 +978,582 @@ func TestLoadInto_Error_BadSection(t *testing.T) {
 		t.Fatal("expected error for nonexistent section")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// LoadFile — whole-file decoding
+// ---------------------------------------------------------------------------
+
+func TestLoadFile_03_Sections(t *testing.T) {
+	type keyed struct {
+		Key  string `ini:"key"`
+		Key2 string `ini:"key2"`
+	}
+	type cfg struct {
+		DefaultKey string `ini:"default_key"`
+		Basic      keyed  `ini:"basic,section"`
+		Mixed      keyed  `ini:"MIXED,section"`
+		Missing    keyed  `ini:"missing,section"`
+	}
+	got, err := LoadFile[cfg](unitPath("03_sections.conf"))
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	want := cfg{
+		DefaultKey: "twelve",
+		Basic:      keyed{Key: "one", Key2: "eleven"},
+		Mixed:      keyed{Key: "three"},
+	}
+	if *got != want {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+}
+
+func TestLoadFile_03_Sections_Strict(t *testing.T) {
+	type keyed struct {
+		Key  string `ini:"key"`
+		Key2 string `ini:"key2"`
+	}
+	type cfg struct {
+		DefaultKey string `ini:"default_key"`
+		Basic      keyed  `ini:"basic,section"`
+	}
+	_, err := LoadFile[cfg](unitPath("03_sections.conf"), WithStrict())
+	if err == nil || !strings.Contains(err.Error(), `section "mixed": unknown section`) {
+		t.Errorf("expected unknown section error, got %v", err)
+	}
+}
+
+func TestLoadFile_Error_BadFile(t *testing.T) {
+	type cfg struct {
+		Host string `ini:"host"`
+	}
+	if _, err := LoadFile[cfg]("/nonexistent/path/to/file.conf"); err == nil {
+		t.Fatal("expected error for nonexistent file")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ParseReader / ParseBytes / ParseString — in-memory sources
+// ---------------------------------------------------------------------------
+
+func TestParseReader(t *testing.T) {
+	f, err := ParseReader("stdin", strings.NewReader("host = localhost\n[db]\nport = 5432\n"))
+	if err != nil {
+		t.Fatalf("ParseReader: %v", err)
+	}
+	if f.Path != "stdin" {
+		t.Errorf("Path = %q, want %q", f.Path, "stdin")
+	}
+	requireSectionCount(t, f, 2)
+	requireParam(t, requireSection(t, f, ""), "host", "localhost")
+	requireParam(t, requireSection(t, f, "db"), "port", "5432")
+}
+
+func TestParseBytes(t *testing.T) {
+	f, err := ParseBytes("bytes.conf", []byte("name = 'hello world'\n"))
+	if err != nil {
+		t.Fatalf("ParseBytes: %v", err)
+	}
+	requireParam(t, requireSection(t, f, ""), "name", "hello world")
+}
+
+func TestParseString_EmptyContents(t *testing.T) {
+	f, err := ParseString("empty.conf", "")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	requireSectionCount(t, f, 1)
+	requireParamCount(t, requireSection(t, f, ""), 0)
+}
+
+func TestParseString_ErrorUsesName(t *testing.T) {
+	_, err := ParseString("inline.conf", "ok = 1\n!bad\n")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.HasPrefix(err.Error(), "inline.conf:2:1:") {
+		t.Errorf("error = %q, want prefix %q", err, "inline.conf:2:1:")
+	}
+}
+
+func TestParseString_IncludeWithoutBaseDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"include", "include '14_included.conf'\n"},
+		{"include_if_exists", "include_if_exists '14_included.conf'\n"},
+		{"include_dir", "include_dir 'subdir'\n"},
+		{"absolute", "include '" + unitPath("includes/14_included.conf") + "'\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseString("inline.conf", tt.content)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), "no base directory") {
+				t.Errorf("error = %q, want substring %q", err, "no base directory")
+			}
+		})
+	}
+}
+
+func TestParseString_IncludeWithBaseDir(t *testing.T) {
+	content := "before = original\ninclude '14_included.conf'\ninclude_dir 'subdir'\nafter = final\n"
+	f, err := ParseString("inline.conf", content, WithBaseDir(unitPath("includes")))
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	if want := unitPath("includes/inline.conf"); f.Path != want {
+		t.Errorf("Path = %q, want %q", f.Path, want)
+	}
+	def := requireSection(t, f, "")
+	requireParam(t, def, "before", "overridden")
+	requireParam(t, def, "included_key", "included_value")
+	requireParam(t, def, "order", "b")
+	requireParam(t, def, "after", "final")
+}
+
+func TestParseString_NestedIncludeRestoresDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTemp(t, dir, "sub/child.conf", "child = yes\n")
+	writeTemp(t, dir, "sibling.conf", "sibling = yes\n")
+
+	content := "include 'sub/child.conf'\ninclude 'sibling.conf'\n"
+	f, err := ParseString("root.conf", content, WithBaseDir(dir))
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	def := requireSection(t, f, "")
+	requireParam(t, def, "child", "yes")
+	requireParam(t, def, "sibling", "yes")
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestParseReader_ReadError(t *testing.T) {
+	_, err := ParseReader("broken", errReader{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "read failed") {
+		t.Errorf("error = %q, want substring %q", err, "read failed")
+	}
+}
+
+func TestLoadReader(t *testing.T) {
+	type cfg struct {
+		Host string `ini:"host"`
+		Port int    `ini:"port"`
+	}
+	got, err := LoadReader[cfg]("stdin", strings.NewReader("[db]\nhost = example.com\nport = 6543\n"), "db")
+	if err != nil {
+		t.Fatalf("LoadReader: %v", err)
+	}
+	if got.Host != "example.com" || got.Port != 6543 {
+		t.Errorf("LoadReader = %+v, want {Host:example.com Port:6543}", *got)
+	}
+}
+
+func TestLoadReader_Options(t *testing.T) {
+	type cfg struct {
+		Before      string `ini:"before"`
+		IncludedKey string `ini:"included_key"`
+	}
+	content := "before = original\ninclude '14_included.conf'\n"
+	got, err := LoadReader[cfg]("inline.conf", strings.NewReader(content), "", WithParseOptions(WithBaseDir(unitPath("includes"))))
+	if err != nil {
+		t.Fatalf("LoadReader: %v", err)
+	}
+	if got.Before != "overridden" || got.IncludedKey != "included_value" {
+		t.Errorf("LoadReader = %+v, want {Before:overridden IncludedKey:included_value}", *got)
+	}
+
+	_, err = LoadReader[cfg]("stdin", strings.NewReader("before = x\nextra = y\n"), "", WithStrict())
+	derrs := requireDecodeErrors(t, err)
+	if len(derrs) != 1 || !strings.Contains(derrs[0].Error(), `key "extra": unknown key`) {
+		t.Errorf("got %v, want one unknown key error for extra", derrs)
+	}
+}
+
+func TestLoadReader_Errors(t *testing.T) {
+	type cfg struct {
+		Host string `ini:"host"`
+	}
+	if _, err := LoadReader[cfg]("stdin", strings.NewReader("!bad\n"), ""); err == nil {
+		t.Error("expected parse error, got nil")
+	}
+	if _, err := LoadReader[cfg]("stdin", strings.NewReader("host = x\n"), "missing"); err == nil {
+		t.Error("expected missing section error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ParseFS — includes resolved within an fs.FS
+// ---------------------------------------------------------------------------
+
+// includesFS is an in-memory tree exercising every include directive.
+var includesFS = fstest.MapFS{
+	"app/main.conf": {Data: []byte(
+		"before = original\n" +
+			"include 'included.conf'\n" +
+			"include_if_exists 'missing.conf'\n" +
+			"include_dir 'conf.d'\n" +
+			"include '/shared/common.conf'\n" +
+			"after = final\n",
+	)},
+	"app/included.conf":       {Data: []byte("included_key = included_value\nbefore = overridden\n")},
+	"app/conf.d/a.conf":       {Data: []byte("order = a\nfrom_a = alpha\n")},
+	"app/conf.d/b.conf":       {Data: []byte("order = b\n")},
+	"app/conf.d/.hidden.conf": {Data: []byte("hidden = yes\n")},
+	"app/conf.d/notes.txt":    {Data: []byte("ignored = yes\n")},
+	"shared/common.conf":      {Data: []byte("[shared]\ncommon = yes\n")},
+}
+
+func TestParseFS(t *testing.T) {
+	f, err := ParseFS(includesFS, "app/main.conf")
+	if err != nil {
+		t.Fatalf("ParseFS: %v", err)
+	}
+	if f.Path != "app/main.conf" {
+		t.Errorf("Path = %q, want %q", f.Path, "app/main.conf")
+	}
+	def := requireSection(t, f, "")
+	requireParam(t, def, "before", "overridden")
+	requireParam(t, def, "included_key", "included_value")
+	requireParam(t, def, "order", "b")
+	requireParam(t, def, "from_a", "alpha")
+	requireParamMissing(t, def, "hidden")
+	requireParamMissing(t, def, "ignored")
+
+	// The absolute include switches to [shared], so "after" lands there.
+	shared := requireSection(t, f, "shared")
+	requireParam(t, shared, "common", "yes")
+	requireParam(t, shared, "after", "final")
+}
+
+func TestParseFS_DirFS(t *testing.T) {
+	f, err := ParseFS(os.DirFS(unitsDir), "includes/16_include_dir.conf")
+	if err != nil {
+		t.Fatalf("ParseFS: %v", err)
+	}
+	def := requireSection(t, f, "")
+	requireParam(t, def, "top", "value")
+	requireParam(t, def, "order", "b")
+}
+
+func TestParseFS_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		file    string
+		wantErr string
+	}{
+		{
+			name:    "root not found",
+			fsys:    fstest.MapFS{},
+			file:    "main.conf",
+			wantErr: "file does not exist",
+		},
+		{
+			name:    "invalid root path",
+			fsys:    fstest.MapFS{},
+			file:    "../main.conf",
+			wantErr: "invalid path",
+		},
+		{
+			name: "include escapes root",
+			fsys: fstest.MapFS{
+				"app/main.conf": {Data: []byte("include '../../etc/passwd'\n")},
+			},
+			file:    "app/main.conf",
+			wantErr: "escapes the filesystem root",
+		},
+		{
+			name: "include_dir escapes root",
+			fsys: fstest.MapFS{
+				"main.conf": {Data: []byte("include_dir '..'\n")},
+			},
+			file:    "main.conf",
+			wantErr: "escapes the filesystem root",
+		},
+		{
+			name: "missing include",
+			fsys: fstest.MapFS{
+				"main.conf": {Data: []byte("include 'missing.conf'\n")},
+			},
+			file:    "main.conf",
+			wantErr: "file does not exist",
+		},
+		{
+			name: "circular include",
+			fsys: fstest.MapFS{
+				"main.conf": {Data: []byte("include 'main.conf'\n")},
+			},
+			file:    "main.conf",
+			wantErr: "circular include detected",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFS(tt.fsys, tt.file)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want substring %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFS_NilFS(t *testing.T) {
+	if _, err := ParseFS(nil, "main.conf"); err == nil {
+		t.Fatal("expected error for nil fsys")
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	type cfg struct {
+		Common string `ini:"common"`
+		After  string `ini:"after"`
+	}
+	got, err := LoadFS[cfg](includesFS, "app/main.conf", "shared")
+	if err != nil {
+		t.Fatalf("LoadFS: %v", err)
+	}
+	if got.Common != "yes" || got.After != "final" {
+		t.Errorf("LoadFS = %+v, want {Common:yes After:final}", *got)
+	}
+
+	if _, err := LoadFS[cfg](includesFS, "app/missing.conf", ""); err == nil {
+		t.Error("expected error for missing file")
+	}
+	if _, err := LoadFS[cfg](includesFS, "app/main.conf", "missing"); err == nil {
+		t.Error("expected error for missing section")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// WithRecovery — report every problem in one pass
+// ---------------------------------------------------------------------------
+
+func TestParse_WithRecovery(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.conf": {Data: []byte(
+			"good = 1\n" +
+				"!bad\n" +
+				"quoted = 'unterminated\n" +
+				"[ok]\n" +
+				"[1bad]\n" +
+				"still_ok = yes\n" +
+				"include 'child.conf'\n" +
+				"include 'missing.conf'\n" +
+				"last = done\n",
+		)},
+		"child.conf": {Data: []byte("child = 1\nescape = '\\z'\n")},
+	}
+
+	f, err := ParseFS(fsys, "main.conf", WithRecovery())
+	if f == nil {
+		t.Fatal("expected partially populated IniFile, got nil")
+	}
+
+	var perrs ParseErrors
+	if !errors.As(err, &perrs) {
+		t.Fatalf("error %T (%v) is not a ParseErrors", err, err)
+	}
+	want := []struct {
+		path string
+		line int
+		kind ErrorKind
+	}{
+		{"main.conf", 2, UnexpectedCharacter},
+		{"main.conf", 3, UnterminatedQuote},
+		{"main.conf", 5, BadSectionHeader},
+		{"child.conf", 2, InvalidEscape},
+		{"main.conf", 8, MissingInclude},
+	}
+	if len(perrs) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(perrs), len(want), err)
+	}
+	for i, w := range want {
+		if perrs[i].Path != w.path || perrs[i].Line != w.line || perrs[i].Kind != w.kind {
+			t.Errorf("errs[%d] = %s:%d %v, want %s:%d %v",
+				i, perrs[i].Path, perrs[i].Line, perrs[i].Kind, w.path, w.line, w.kind)
+		}
+	}
+
+	// Good lines around the bad ones are still parsed.
+	requireParam(t, requireSection(t, f, ""), "good", "1")
+	requireParamMissing(t, requireSection(t, f, ""), "quoted")
+	ok := requireSection(t, f, "ok")
+	requireParam(t, ok, "still_ok", "yes")
+	requireParam(t, ok, "child", "1")
+	requireParam(t, ok, "last", "done")
+
+	// errors.As reaches individual errors through ParseErrors.
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr != perrs[0] {
+		t.Errorf("errors.As(*ParseError) = %v, want first error", perr)
+	}
+	if lines := strings.Split(err.Error(), "\n"); len(lines) != len(want) {
+		t.Errorf("Error() has %d lines, want %d", len(lines), len(want))
+	}
+}
+
+// unreadableFS is a MapFS in which the file named fail cannot be read.
+type unreadableFS struct {
+	fstest.MapFS
+	fail string
+}
+
+func (u unreadableFS) Open(name string) (fs.File, error) {
+	if name == u.fail {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return u.MapFS.Open(name)
+}
+
+func (u unreadableFS) ReadFile(name string) ([]byte, error) {
+	if name == u.fail {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return u.MapFS.ReadFile(name)
+}
+
+func TestParse_WithRecovery_IncludeDir(t *testing.T) {
+	fsys := unreadableFS{
+		MapFS: fstest.MapFS{
+			"main.conf":     {Data: []byte("include_dir 'conf.d'\nlast = done\n")},
+			"conf.d/a.conf": {Data: []byte("a = 1\n")},
+			"conf.d/b.conf": {Data: []byte("b = 1\n")},
+			"conf.d/c.conf": {Data: []byte("!bad\nc = 1\n")},
+		},
+		fail: "conf.d/b.conf",
+	}
+
+	f, err := ParseFS(fsys, "main.conf", WithRecovery())
+	if f == nil {
+		t.Fatalf("expected partially populated IniFile, got nil (%v)", err)
+	}
+	var perrs ParseErrors
+	if !errors.As(err, &perrs) || len(perrs) != 2 {
+		t.Fatalf("expected two ParseErrors, got %v", err)
+	}
+	if perrs[0].Path != "main.conf" || perrs[0].Line != 1 || perrs[0].Kind != BadInclude {
+		t.Errorf("perrs[0] = %v, want a BadInclude at main.conf:1", perrs[0])
+	}
+	if perrs[1].Path != "conf.d/c.conf" || perrs[1].Line != 1 {
+		t.Errorf("perrs[1] = %v, want an error at conf.d/c.conf:1", perrs[1])
+	}
+	def := requireSection(t, f, "")
+	requireParam(t, def, "a", "1")
+	requireParam(t, def, "c", "1")
+	requireParam(t, def, "last", "done")
+}
+
+func TestParse_WithRecovery_NoErrors(t *testing.T) {
+	f, err := Parse(unitPath("11_duplicates.conf"), WithRecovery())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	requireParam(t, requireSection(t, f, ""), "key", "third")
+}
+
+func TestParse_WithRecovery_ErrorFiles(t *testing.T) {
+	// Every error fixture yields a non-nil IniFile and at least one error.
+	entries, err := os.ReadDir(filepath.Join(unitsDir, "errors"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Run(entry.Name(), func(t *testing.T) {
+			f, err := Parse(unitPath("errors/"+entry.Name()), WithRecovery())
+			if f == nil {
+				t.Fatal("expected IniFile, got nil")
+			}
+			var perrs ParseErrors
+			if !errors.As(err, &perrs) || len(perrs) == 0 {
+				t.Errorf("expected ParseErrors, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParse_WithRecovery_MissingRoot(t *testing.T) {
+	f, err := Parse(nonExistingPath("missing.conf"), WithRecovery())
+	if err == nil || f != nil {
+		t.Errorf("Parse = (%v, %v), want (nil, error)", f, err)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Provenance — Param.Origin, Param.Shadowed, Section.Origins
+// ---------------------------------------------------------------------------
+
+func TestParse_Provenance_Duplicates(t *testing.T) {
+	path := unitPath("11_duplicates.conf")
+	f := requireLoad(t, "11_duplicates.conf")
+
+	p, _ := requireSection(t, f, "").GetParam("key")
+	if want := (Location{Path: path, Line: 6, Column: 1}); p.Origin != want {
+		t.Errorf("key Origin = %v, want %v", p.Origin, want)
+	}
+	if len(p.Shadowed) != 2 {
+		t.Fatalf("key len(Shadowed) = %d, want 2", len(p.Shadowed))
+	}
+	for i, want := range []struct {
+		value string
+		line  int
+	}{{"first", 4}, {"second", 5}} {
+		got := p.Shadowed[i]
+		if got.Value != want.value || got.Origin.Line != want.line {
+			t.Errorf("Shadowed[%d] = %q at line %d, want %q at line %d",
+				i, got.Value, got.Origin.Line, want.value, want.line)
+		}
+	}
+
+	sectionA := requireSection(t, f, "section_a")
+	if len(sectionA.Origins) != 2 || sectionA.Origins[0].Line != 8 || sectionA.Origins[1].Line != 18 {
+		t.Errorf("section_a Origins = %v, want lines 8 and 18", sectionA.Origins)
+	}
+	def := requireSection(t, f, "")
+	if len(def.Origins) != 1 || def.Origins[0].Line != 13 {
+		t.Errorf("default Origins = %v, want [default] header on line 13", def.Origins)
+	}
+}
+
+func TestParse_Provenance_IncludeDir(t *testing.T) {
+	f := requireLoad(t, "includes/16_include_dir.conf")
+	def := requireSection(t, f, "")
+
+	order, _ := def.GetParam("order")
+	if want := (Location{Path: unitPath("includes/subdir/b.conf"), Line: 3, Column: 1}); order.Origin != want {
+		t.Errorf("order Origin = %v, want %v", order.Origin, want)
+	}
+	if len(order.Shadowed) != 1 || order.Shadowed[0].Origin.Path != unitPath("includes/subdir/a.conf") {
+		t.Errorf("order Shadowed = %v, want one definition from a.conf", order.Shadowed)
+	}
+
+	top, _ := def.GetParam("top")
+	if top.Origin.Path != unitPath("includes/16_include_dir.conf") || len(top.Shadowed) != 0 {
+		t.Errorf("top = %v shadowing %v, want root file and no history", top.Origin, top.Shadowed)
+	}
+}
+
+func TestParse_Provenance_Column(t *testing.T) {
+	f, err := ParseString("app.conf", "[db]\n\t  port = 5432\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := requireSection(t, f, "db").GetParam("port")
+	if want := (Location{Path: "app.conf", Line: 2, Column: 4}); p.Origin != want {
+		t.Errorf("Origin = %v, want %v", p.Origin, want)
+	}
+}
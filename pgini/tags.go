@@ -0,0 +1,438 @@
+// Struct tags map Go struct fields to PGINI keys and sections. A tag has the
+// form `ini:"name,option,..."`, where name is the parameter key (or section
+// name) and each option changes how the field is mapped:
+//
+//   - section: the field is a struct decoded from the whole [name] section
+//     rather than from a single key (see UnmarshalFile and MarshalFile)
+//   - sections: the field is a map[string]S or map[string]*S, where S is a
+//     struct, holding every section whose name matches the path.Match
+//     pattern name, keyed by section name (see UnmarshalSections)
+//   - unit=UNIT: the implied unit of a bare number, such as `unit=ms` for a
+//     time.Duration field or `unit=kB` for an integer field (see units.go)
+//   - bytes: the same as unit=B; the integer field holds a size in bytes
+//   - sep=C: the list separator of a slice field, a punctuation character
+//     other than '"'; the default is a comma (see lists.go)
+//   - multi: repeated definitions of the key accumulate into a slice field
+//   - required: decoding fails when the key is missing
+//   - inline: the field is a struct, or a pointer to one, whose fields are
+//     mapped as if they were fields of the outer struct; the tag has no name,
+//     as in `ini:",inline"`
+//   - prefix=P: with inline, P is prepended to the keys of the inline fields,
+//     as in `ini:",inline,prefix=tls_"`
+//   - remain: the field is a map[string]string or map[string]any that holds
+//     every parameter of the section that no other field maps, keyed by its
+//     lowercased key; the tag has no name, as in `ini:",remain"`. A struct has
+//     at most one remain field, and its keys are written back in sorted order.
+//
+// An embedded struct field without an `ini` tag is inlined, as in Go's field
+// promotion. Keys, and section names, must be unique across the outer struct
+// and every inline struct.
+//
+// A separate `validate:"RULE,..."` tag checks the decoded value; see
+// validate.go.
+//
+// A separate `default:"VALUE"` tag gives the value of a key field whose key
+// is missing, decoded as if the file had set it. It is a tag of its own so that
+// the value may contain commas.
+//
+// A separate `env:"NAME"` tag names an environment variable that, when set,
+// overrides the key of a key field while decoding, taking precedence over the
+// file and the default; see env.go.
+//
+// Fields without an `ini` tag, with an empty tag, or that are unexported are
+// skipped.
+
+package pgini
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// fieldTag is a parsed `ini` struct tag.
+type fieldTag struct {
+	// name is the parameter key, or the section name of a section field.
+	name string
+	// section maps the field to a whole section rather than a single key.
+	section bool
+	// sections maps the field to every section matching name, a pattern.
+	sections bool
+	// unit is the implied unit of bare numbers, or empty.
+	unit string
+	// sep separates the elements of a slice field, or is 0 for the default.
+	sep rune
+	// multi accumulates repeated definitions into a slice field.
+	multi bool
+	// required makes a missing key an error.
+	required bool
+	// defaultValue is the value of the field's `default` tag, if hasDefault.
+	defaultValue string
+	hasDefault   bool
+	// env is the value of the field's `env` tag: the environment variable
+	// that overrides the key, or empty.
+	env string
+	// inline maps the fields of a struct field as fields of the outer struct.
+	inline bool
+	// prefix is prepended to the keys of an inline struct's fields.
+	prefix string
+	// remain collects the parameters that no other field maps into a map.
+	remain bool
+}
+
+// parseFieldTag parses the value of an `ini` struct tag. It returns an error
+// for a missing name or an unknown option.
+func parseFieldTag(tag string) (fieldTag, error) {
+	name, rest, _ := strings.Cut(tag, ",")
+	ft := fieldTag{name: strings.TrimSpace(name)}
+
+	bytes := false // the bytes option, resolved to unit=B after the loop
+	others := 0    // options other than inline, prefix, and remain, which those exclude
+	for rest != "" {
+		var opt string
+		opt, rest, _ = strings.Cut(rest, ",")
+		opt = strings.TrimSpace(opt)
+		if opt != "" && opt != "inline" && opt != "remain" && !strings.HasPrefix(opt, "prefix=") {
+			others++
+		}
+		if prefix, ok := strings.CutPrefix(opt, "prefix="); ok {
+			if !isKeyPrefix(prefix) {
+				return fieldTag{}, fmt.Errorf("invalid ini tag %q: prefix %q is not a valid key prefix", tag, prefix)
+			}
+			ft.prefix = prefix
+			continue
+		}
+		if unit, ok := strings.CutPrefix(opt, "unit="); ok {
+			if !isUnitName(unit) {
+				return fieldTag{}, fmt.Errorf("invalid ini tag %q: unknown unit %q", tag, unit)
+			}
+			ft.unit = unit
+			continue
+		}
+		if sep, ok := strings.CutPrefix(opt, "sep="); ok {
+			r := []rune(sep)
+			if len(r) != 1 || !isListSep(r[0]) {
+				return fieldTag{}, fmt.Errorf("invalid ini tag %q: separator must be one punctuation character other than '\"', got %q", tag, sep)
+			}
+			ft.sep = r[0]
+			continue
+		}
+		switch opt {
+		case "":
+			// Tolerate stray commas, as in `ini:"host,"`.
+		case "section":
+			ft.section = true
+		case "sections":
+			ft.sections = true
+		case "bytes":
+			bytes = true
+		case "multi":
+			ft.multi = true
+		case "required":
+			ft.required = true
+		case "inline":
+			ft.inline = true
+		case "remain":
+			ft.remain = true
+		default:
+			return fieldTag{}, fmt.Errorf("invalid ini tag %q: unknown option %q", tag, opt)
+		}
+	}
+
+	if ft.remain {
+		switch {
+		case ft.name != "":
+			return fieldTag{}, fmt.Errorf("invalid ini tag %q: remain fields take no name", tag)
+		case ft.inline || ft.prefix != "" || others > 0:
+			return fieldTag{}, fmt.Errorf("invalid ini tag %q: remain takes no other options", tag)
+		}
+		return ft, nil
+	}
+	if ft.inline {
+		switch {
+		case ft.name != "":
+			return fieldTag{}, fmt.Errorf("invalid ini tag %q: inline fields take no name", tag)
+		case others > 0:
+			return fieldTag{}, fmt.Errorf("invalid ini tag %q: inline takes no options other than prefix", tag)
+		}
+		return ft, nil
+	}
+	if ft.name == "" {
+		return fieldTag{}, fmt.Errorf("invalid ini tag %q: missing name", tag)
+	}
+	if ft.prefix != "" {
+		return fieldTag{}, fmt.Errorf("invalid ini tag %q: prefix requires inline", tag)
+	}
+	if bytes {
+		if ft.unit != "" {
+			return fieldTag{}, fmt.Errorf("invalid ini tag %q: bytes and unit are exclusive", tag)
+		}
+		ft.unit = "B"
+	}
+	if ft.required && ft.isSection() {
+		return fieldTag{}, fmt.Errorf("invalid ini tag %q: required does not apply to section fields", tag)
+	}
+	if ft.section && ft.sections {
+		return fieldTag{}, fmt.Errorf("invalid ini tag %q: section and sections are exclusive", tag)
+	}
+	if ft.sections {
+		if _, err := path.Match(ft.name, ""); err != nil {
+			return fieldTag{}, fmt.Errorf("invalid ini tag %q: %w", tag, err)
+		}
+	}
+	return ft, nil
+}
+
+// isSection reports whether the field maps to sections rather than a key.
+func (ft fieldTag) isSection() bool {
+	return ft.section || ft.sections
+}
+
+// isRemainMap reports whether t is the type of a remain field: a map with
+// string keys and values that are strings or interfaces without methods, such
+// as map[string]string or map[string]any.
+func isRemainMap(t reflect.Type) bool {
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return false
+	}
+	elem := t.Elem()
+	return elem.Kind() == reflect.String || elem.Kind() == reflect.Interface && elem.NumMethod() == 0
+}
+
+// isKeyPrefix reports whether prefix may begin a PGINI key: a letter followed
+// by letters and digits.
+func isKeyPrefix(prefix string) bool {
+	if prefix == "" || !isLetter(rune(prefix[0])) {
+		return false
+	}
+	return !strings.ContainsFunc(prefix, func(r rune) bool { return !isIdentChar(r) })
+}
+
+// listSep returns the separator of a slice field's elements.
+func (ft fieldTag) listSep() rune {
+	if ft.sep == 0 {
+		return defaultListSep
+	}
+	return ft.sep
+}
+
+// taggedField is an exported struct field with a non-empty `ini` tag.
+type taggedField struct {
+	// index is the field's index sequence within the outer struct, as for
+	// reflect.Value.FieldByIndex; it is longer than one for a field of an
+	// embedded or inline struct.
+	index []int
+	// name is the field's Go selector from the outer struct, such as "Port"
+	// or "TLS.CertFile", for messages.
+	name string
+	// def holds the field's name, type, and tags.
+	def reflect.StructField
+	// tag is the field's parsed `ini` tag, with any inline prefix applied.
+	tag fieldTag
+	// rules are the field's parsed `validate` tag.
+	rules []validateRule
+	// receiver is the length of the prefix of index that leads to the struct
+	// whose custom methods apply to the field: the struct the field is
+	// promoted to, so 0 for the outer struct.
+	receiver int
+	// unmarshalMethod and marshalMethod are the field's custom
+	// Unmarshal<FieldName> and Marshal<FieldName> methods, or nil.
+	unmarshalMethod *customMethod
+	marshalMethod   *customMethod
+}
+
+// compileFields returns the mapped fields of structType in declaration order,
+// with the fields of embedded and inline structs in place of the struct, and
+// their custom methods resolved. It returns an error when two fields map to
+// the same key, or the same section, or when a custom method is ambiguous.
+// Use taggedFields, which caches the result.
+func compileFields(structType reflect.Type) ([]taggedField, error) {
+	fields, err := appendFields(nil, structType, nil, 0, "", "", []reflect.Type{structType})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]string)     // lowercased key to field name
+	sections := make(map[string]string) // lowercased section name to field name
+	remain := ""                        // name of the remain field
+	for _, field := range fields {
+		if field.tag.remain {
+			if remain != "" {
+				return nil, fmt.Errorf("field %s: remain field collides with field %s", field.name, remain)
+			}
+			remain = field.name
+			continue
+		}
+		kind, seen := "key", keys
+		if field.tag.isSection() {
+			kind, seen = "section", sections
+		}
+		name := strings.ToLower(field.tag.name)
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("field %s: %s %q collides with field %s", field.name, kind, name, other)
+		}
+		seen[name] = field.name
+	}
+
+	for i := range fields {
+		if err := resolveMethods(structType, &fields[i]); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+// appendFields appends the mapped fields of structType to fields. index and
+// selector locate structType within the outer struct, receiver is the
+// taggedField.receiver of its fields, prefix is prepended to its keys, and
+// parents lists the struct types being inlined, to reject a struct that
+// inlines itself.
+func appendFields(fields []taggedField, structType reflect.Type, index []int, receiver int, selector, prefix string, parents []reflect.Type) ([]taggedField, error) {
+	for i := range structType.NumField() {
+		fieldDef := structType.Field(i)
+		fieldIndex := append(slices.Clone(index), i)
+		fieldName := fieldDef.Name
+		if selector != "" {
+			fieldName = selector + "." + fieldDef.Name
+		}
+
+		var ft fieldTag
+		tag, ok := fieldDef.Tag.Lookup("ini")
+		switch {
+		case !ok && fieldDef.Anonymous:
+			ft.inline = true
+		case !ok || tag == "":
+			continue
+		default:
+			var err error
+			if ft, err = parseFieldTag(tag); err != nil {
+				return nil, fmt.Errorf("field %s: %w", fieldName, err)
+			}
+		}
+
+		if ft.inline {
+			inner := fieldDef.Type
+			if inner.Kind() == reflect.Pointer {
+				inner = inner.Elem()
+			}
+			switch {
+			case inner.Kind() != reflect.Struct && !ok:
+				continue // an embedded non-struct type without a tag
+			case inner.Kind() != reflect.Struct:
+				return nil, fmt.Errorf("field %s: inline field must be a struct or struct pointer, got %s", fieldName, fieldDef.Type)
+			case !fieldDef.IsExported() && (!fieldDef.Anonymous || fieldDef.Type.Kind() == reflect.Pointer):
+				continue // unexported fields cannot be set, except through an embedded struct
+			case slices.Contains(parents, inner):
+				return nil, fmt.Errorf("field %s: inline struct %s contains itself", fieldName, inner)
+			}
+			for _, other := range []string{"default", "validate", "env"} {
+				if _, ok := fieldDef.Tag.Lookup(other); ok {
+					return nil, fmt.Errorf("field %s: %s tag does not apply to inline fields", fieldName, other)
+				}
+			}
+
+			innerSelector, innerReceiver := fieldName, len(fieldIndex)
+			if fieldDef.Anonymous {
+				// Promoted fields, and methods, belong to the outer struct.
+				innerSelector, innerReceiver = selector, receiver
+			}
+			var err error
+			fields, err = appendFields(fields, inner, fieldIndex, innerReceiver, innerSelector, prefix+ft.prefix, append(parents, inner))
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !fieldDef.IsExported() {
+			continue
+		}
+		if ft.remain {
+			if !isRemainMap(fieldDef.Type) {
+				return nil, fmt.Errorf("field %s: remain field must be a map[string]string or map[string]any, got %s", fieldName, fieldDef.Type)
+			}
+			for _, other := range []string{"default", "validate", "env"} {
+				if _, ok := fieldDef.Tag.Lookup(other); ok {
+					return nil, fmt.Errorf("field %s: %s tag does not apply to remain fields", fieldName, other)
+				}
+			}
+			fields = append(fields, taggedField{index: fieldIndex, name: fieldName, def: fieldDef, tag: ft, receiver: receiver})
+			continue
+		}
+		ft.name = prefix + ft.name
+		if ft.unit != "" && !ft.isSection() {
+			if err := checkUnitType(fieldDef.Type, ft.unit); err != nil {
+				return nil, fmt.Errorf("field %s: %w", fieldName, err)
+			}
+		}
+
+		if dflt, ok := fieldDef.Tag.Lookup("default"); ok {
+			if ft.isSection() {
+				return nil, fmt.Errorf("field %s: default tag does not apply to section fields", fieldName)
+			}
+			if ft.required {
+				return nil, fmt.Errorf("field %s: required and default tags are exclusive", fieldName)
+			}
+			ft.defaultValue, ft.hasDefault = dflt, true
+		}
+		if env, ok := fieldDef.Tag.Lookup("env"); ok {
+			if ft.isSection() {
+				return nil, fmt.Errorf("field %s: env tag does not apply to section fields", fieldName)
+			}
+			if !identifierRe.MatchString(env) {
+				return nil, fmt.Errorf("field %s: invalid env tag %q: must match [A-Za-z_][A-Za-z0-9_]*", fieldName, env)
+			}
+			ft.env = env
+		}
+		var rules []validateRule
+		if v, ok := fieldDef.Tag.Lookup("validate"); ok {
+			if ft.isSection() {
+				return nil, fmt.Errorf("field %s: validate tag does not apply to section fields", fieldName)
+			}
+			var err error
+			if rules, err = parseValidateTag(v); err != nil {
+				return nil, fmt.Errorf("field %s: %w", fieldName, err)
+			}
+			if err := compileRules(v, rules, fieldDef.Type, ft); err != nil {
+				return nil, fmt.Errorf("field %s: %w", fieldName, err)
+			}
+		}
+		fields = append(fields, taggedField{index: fieldIndex, name: fieldName, def: fieldDef, tag: ft, rules: rules, receiver: receiver})
+	}
+	return fields, nil
+}
+
+// fieldAt returns the value of field within structValue, following embedded
+// and inline struct fields, and the struct whose custom methods apply to it
+// (see taggedField.receiver). A nil struct pointer on the way is allocated
+// when alloc is set; otherwise fieldAt reports false.
+func fieldAt(structValue reflect.Value, field taggedField, alloc bool) (receiver, fieldValue reflect.Value, ok bool) {
+	v, receiver := structValue, structValue
+	for depth, i := range field.index[:len(field.index)-1] {
+		v = v.Field(i)
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if depth+1 == field.receiver {
+			receiver = v
+		}
+	}
+	return receiver, v.Field(field.index[len(field.index)-1]), true
+}
+
+// remainField returns the remain field of fields, if any.
+func remainField(fields []taggedField) (taggedField, bool) {
+	for _, field := range fields {
+		if field.tag.remain {
+			return field, true
+		}
+	}
+	return taggedField{}, false
+}
@@ -0,0 +1,341 @@
+package pgini
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// --- parseFieldTag tests ---
+
+func TestParseFieldTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want fieldTag
+	}{
+		{"host", fieldTag{name: "host"}},
+		{"database,section", fieldTag{name: "database", section: true}},
+		{" database , section ", fieldTag{name: "database", section: true}},
+		{"host,", fieldTag{name: "host"}},
+		{"database,,section", fieldTag{name: "database", section: true}},
+		{"db_*,sections", fieldTag{name: "db_*", sections: true}},
+		{"statement_timeout,unit=ms", fieldTag{name: "statement_timeout", unit: "ms"}},
+		{"work_mem,unit=kB", fieldTag{name: "work_mem", unit: "kB"}},
+		{"work_mem,bytes", fieldTag{name: "work_mem", unit: "B"}},
+		{"paths,sep=:", fieldTag{name: "paths", sep: ':'}},
+		{"allow,multi", fieldTag{name: "allow", multi: true}},
+		{"port,required", fieldTag{name: "port", required: true}},
+		{",inline", fieldTag{inline: true}},
+		{",inline,prefix=tls_", fieldTag{inline: true, prefix: "tls_"}},
+		{",remain", fieldTag{remain: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := parseFieldTag(tt.tag)
+			if err != nil {
+				t.Fatalf("parseFieldTag(%q): %v", tt.tag, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseFieldTag(%q) = %+v, want %+v", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFieldTag_Errors(t *testing.T) {
+	tests := []struct {
+		tag     string
+		wantErr string
+	}{
+		{",section", "missing name"},
+		{"host,sectoin", `unknown option "sectoin"`},
+		{"db,section,sections", "exclusive"},
+		{"db_[,sections", "syntax error in pattern"},
+		{"timeout,unit=weeks", `unknown unit "weeks"`},
+		{"timeout,unit=", `unknown unit ""`},
+		{"work_mem,unit=KB", `unknown unit "KB"`},
+		{"work_mem,bytes,unit=kB", "bytes and unit are exclusive"},
+		{"paths,sep=", "separator must be one punctuation character"},
+		{"db,section,required", "required does not apply to section fields"},
+		{"paths,sep=::", "separator must be one punctuation character"},
+		{`paths,sep="`, "separator must be one punctuation character"},
+		{"paths,sep=x", "separator must be one punctuation character"},
+		{"tls,inline", "inline fields take no name"},
+		{",inline,required", "inline takes no options other than prefix"},
+		{"host,prefix=tls_", "prefix requires inline"},
+		{",inline,prefix=1tls", `prefix "1tls" is not a valid key prefix`},
+		{",inline,prefix=", `prefix "" is not a valid key prefix`},
+		{"labels,remain", "remain fields take no name"},
+		{",remain,required", "remain takes no other options"},
+		{",inline,remain", "remain takes no other options"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			_, err := parseFieldTag(tt.tag)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseFieldTag(%q) error = %v, want %q", tt.tag, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// --- taggedFields tests ---
+
+func TestTaggedFields(t *testing.T) {
+	type sample struct {
+		Host     string `ini:"host"`
+		NoTag    string
+		Empty    string `ini:""`
+		hidden   string `ini:"hidden"`
+		Database struct {
+			Name string `ini:"name"`
+		} `ini:"database,section"`
+	}
+	_ = sample{}.hidden
+
+	fields, err := taggedFields(reflect.TypeFor[sample]())
+	if err != nil {
+		t.Fatalf("taggedFields: %v", err)
+	}
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 fields, got %d: %+v", len(fields), fields)
+	}
+	if fields[0].def.Name != "Host" || !slices.Equal(fields[0].index, []int{0}) || fields[0].tag.section {
+		t.Errorf("fields[0] = %+v, want Host at index 0", fields[0])
+	}
+	if fields[1].def.Name != "Database" || !slices.Equal(fields[1].index, []int{4}) || !fields[1].tag.section {
+		t.Errorf("fields[1] = %+v, want section field Database at index 4", fields[1])
+	}
+}
+
+func TestTaggedFields_BadTag(t *testing.T) {
+	type sample struct {
+		Host string `ini:"host,bogus"`
+	}
+	_, err := taggedFields(reflect.TypeFor[sample]())
+	if err == nil || !strings.Contains(err.Error(), "field Host") {
+		t.Errorf("expected error naming field Host, got %v", err)
+	}
+}
+
+func TestTaggedFields_Default(t *testing.T) {
+	type sample struct {
+		Port  int      `ini:"port" default:"5432"`
+		Hosts []string `ini:"hosts" default:"a, b"`
+		Empty string   `ini:"empty" default:""`
+		None  string   `ini:"none"`
+	}
+	fields, err := taggedFields(reflect.TypeFor[sample]())
+	if err != nil {
+		t.Fatalf("taggedFields: %v", err)
+	}
+	want := []fieldTag{
+		{name: "port", defaultValue: "5432", hasDefault: true},
+		{name: "hosts", defaultValue: "a, b", hasDefault: true},
+		{name: "empty", hasDefault: true},
+		{name: "none"},
+	}
+	for i, f := range fields {
+		if f.tag != want[i] {
+			t.Errorf("fields[%d].tag = %+v, want %+v", i, f.tag, want[i])
+		}
+	}
+}
+
+func TestTaggedFields_DefaultOnSection(t *testing.T) {
+	type sample struct {
+		Database struct {
+			Name string `ini:"name"`
+		} `ini:"database,section" default:"x"`
+	}
+	_, err := taggedFields(reflect.TypeFor[sample]())
+	if err == nil || !strings.Contains(err.Error(), "default tag does not apply to section fields") {
+		t.Errorf("expected section default error, got %v", err)
+	}
+}
+
+func TestTaggedFields_RequiredWithDefault(t *testing.T) {
+	type sample struct {
+		Port int `ini:"port,required" default:"5432"`
+	}
+	_, err := taggedFields(reflect.TypeFor[sample]())
+	if err == nil || !strings.Contains(err.Error(), "required and default tags are exclusive") {
+		t.Errorf("expected exclusive error, got %v", err)
+	}
+}
+
+func TestTaggedFields_UnitErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     reflect.Type
+		wantErr string
+	}{
+		{"time unit on int", reflect.TypeFor[struct {
+			Timeout int `ini:"timeout,unit=ms"`
+		}](), "field Timeout: time unit \"ms\" requires a time.Duration field, got int"},
+		{"memory unit on duration", reflect.TypeFor[struct {
+			Timeout time.Duration `ini:"timeout,unit=kB"`
+		}](), "field Timeout: memory unit \"kB\" does not apply to a time.Duration field"},
+		{"bytes on string", reflect.TypeFor[struct {
+			WorkMem string `ini:"work_mem,bytes"`
+		}](), "field WorkMem: memory unit \"B\" requires an integer field, got string"},
+		{"time unit on int slice", reflect.TypeFor[struct {
+			Timeouts []*int `ini:"timeouts,unit=s"`
+		}](), "field Timeouts: time unit \"s\" requires a time.Duration field, got int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := taggedFields(tt.typ)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	type units struct {
+		Timeouts []*time.Duration `ini:"timeouts,unit=s"`
+		WorkMem  *uint32          `ini:"work_mem,unit=kB"`
+		Color    color            `ini:"color,unit=ms"`
+	}
+	if _, err := taggedFields(reflect.TypeFor[units]()); err != nil {
+		t.Errorf("taggedFields: %v", err)
+	}
+}
+
+// --- embedded and inline field tests ---
+
+type tagsTLS struct {
+	CertFile string `ini:"cert_file"`
+	KeyFile  string `ini:"key_file"`
+}
+
+type tagsLimits struct {
+	MaxConns int `ini:"max_conns"`
+}
+
+func TestTaggedFields_Inline(t *testing.T) {
+	type sample struct {
+		tagsLimits
+		Host    string   `ini:"host"`
+		TLS     tagsTLS  `ini:",inline,prefix=tls_"`
+		Backup  *tagsTLS `ini:",inline,prefix=backup_"`
+		Ignored tagsTLS
+		*strings.Builder
+	}
+	fields, err := taggedFields(reflect.TypeFor[sample]())
+	if err != nil {
+		t.Fatalf("taggedFields: %v", err)
+	}
+
+	type want struct {
+		name  string
+		key   string
+		index []int
+	}
+	wants := []want{
+		{"MaxConns", "max_conns", []int{0, 0}},
+		{"Host", "host", []int{1}},
+		{"TLS.CertFile", "tls_cert_file", []int{2, 0}},
+		{"TLS.KeyFile", "tls_key_file", []int{2, 1}},
+		{"Backup.CertFile", "backup_cert_file", []int{3, 0}},
+		{"Backup.KeyFile", "backup_key_file", []int{3, 1}},
+	}
+	if len(fields) != len(wants) {
+		t.Fatalf("got %d fields, want %d: %+v", len(fields), len(wants), fields)
+	}
+	for i, w := range wants {
+		f := fields[i]
+		if f.name != w.name || f.tag.name != w.key || !slices.Equal(f.index, w.index) {
+			t.Errorf("fields[%d] = %s %q %v, want %s %q %v", i, f.name, f.tag.name, f.index, w.name, w.key, w.index)
+		}
+	}
+}
+
+func TestTaggedFields_InlineErrors(t *testing.T) {
+	type recursive struct {
+		Name string     `ini:"name"`
+		Next *recursive `ini:",inline,prefix=next_"`
+	}
+	tests := []struct {
+		name    string
+		typ     reflect.Type
+		wantErr string
+	}{
+		{"key collision", reflect.TypeFor[struct {
+			CertFile string  `ini:"tls_cert_file"`
+			TLS      tagsTLS `ini:",inline,prefix=tls_"`
+		}](), `field TLS.CertFile: key "tls_cert_file" collides with field CertFile`},
+		{"embedded collision", reflect.TypeFor[struct {
+			tagsLimits
+			Limits tagsLimits `ini:",inline"`
+		}](), `field Limits.MaxConns: key "max_conns" collides with field MaxConns`},
+		{"case-insensitive collision", reflect.TypeFor[struct {
+			A string `ini:"host"`
+			B string `ini:"HOST"`
+		}](), `field B: key "host" collides with field A`},
+		{"section collision", reflect.TypeFor[struct {
+			A tagsTLS `ini:"tls,section"`
+			B tagsTLS `ini:"tls,section"`
+		}](), `field B: section "tls" collides with field A`},
+		{"not a struct", reflect.TypeFor[struct {
+			Port int `ini:",inline"`
+		}](), "inline field must be a struct or struct pointer, got int"},
+		{"default on inline", reflect.TypeFor[struct {
+			TLS tagsTLS `ini:",inline" default:"x"`
+		}](), "default tag does not apply to inline fields"},
+		{"bad inner tag", reflect.TypeFor[struct {
+			TLS struct {
+				Port int `ini:"port,bogus"`
+			} `ini:",inline"`
+		}](), "field TLS.Port: invalid ini tag"},
+		{"recursive", reflect.TypeFor[recursive](), "contains itself"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := taggedFields(tt.typ)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// --- remain field tests ---
+
+func TestTaggedFields_RemainErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     reflect.Type
+		wantErr string
+	}{
+		{"two remain fields", reflect.TypeFor[struct {
+			A map[string]string `ini:",remain"`
+			B map[string]any    `ini:",remain"`
+		}](), "field B: remain field collides with field A"},
+		{"remain in inline struct", reflect.TypeFor[struct {
+			A     map[string]string `ini:",remain"`
+			Inner struct {
+				B map[string]string `ini:",remain"`
+			} `ini:",inline"`
+		}](), "field Inner.B: remain field collides with field A"},
+		{"not a map", reflect.TypeFor[struct {
+			Extra string `ini:",remain"`
+		}](), "remain field must be a map[string]string or map[string]any, got string"},
+		{"map of int", reflect.TypeFor[struct {
+			Extra map[string]int `ini:",remain"`
+		}](), "remain field must be a map[string]string or map[string]any, got map[string]int"},
+		{"default on remain", reflect.TypeFor[struct {
+			Extra map[string]string `ini:",remain" default:"x"`
+		}](), "default tag does not apply to remain fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := taggedFields(tt.typ)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+// Units decode and encode PostgreSQL-style quantities such as "30s", "5min",
+// or "100 ms". A value is a number, optionally followed by whitespace and a
+// case-sensitive unit. A bare number is in the field's implied unit, set with
+// the `unit=` tag option; without one, a bare number in a time.Duration field
+// is a count of nanoseconds, as for any other int64.
+//
+// Time units, for time.Duration fields: us, ms, s, min, h, d.
+//
+// Memory units, for integer fields with the `bytes` or `unit=` tag option:
+// B, kB, MB, GB, TB. As in PostgreSQL, these are multiples of 1024. The field
+// holds a count of its implied unit, so "4MB" decodes to 4096 with `unit=kB`.
+
+package pgini
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeUnits are the PostgreSQL time units, largest first.
+var timeUnits = []struct {
+	name string
+	size time.Duration
+}{
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"min", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+}
+
+// memoryUnits are the PostgreSQL memory units, largest first.
+var memoryUnits = []struct {
+	name string
+	size int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"kB", 1 << 10},
+	{"B", 1},
+}
+
+// durationType is the reflect.Type of time.Duration.
+var durationType = reflect.TypeFor[time.Duration]()
+
+// timeUnit returns the size of the named time unit.
+func timeUnit(name string) (time.Duration, bool) {
+	for _, u := range timeUnits {
+		if u.name == name {
+			return u.size, true
+		}
+	}
+	return 0, false
+}
+
+// memoryUnit returns the size in bytes of the named memory unit.
+func memoryUnit(name string) (int64, bool) {
+	for _, u := range memoryUnits {
+		if u.name == name {
+			return u.size, true
+		}
+	}
+	return 0, false
+}
+
+// isUnitName reports whether name is a unit accepted by the `unit=` tag option.
+func isUnitName(name string) bool {
+	_, isTime := timeUnit(name)
+	_, isMemory := memoryUnit(name)
+	return isTime || isMemory
+}
+
+// checkUnitType reports an error when unit does not apply to t, the type of a
+// field tagged with it: time units need a time.Duration and memory units an
+// integer, after pointers and slice elements are followed. Types with their
+// own IniUnmarshaler, IniMarshaler, or encoding.Text* methods ignore the unit.
+func checkUnitType(t reflect.Type, unit string) error {
+	for {
+		if implementsUnmarshaler(t) || implementsUnmarshaler(reflect.PointerTo(t)) || implementsMarshaler(t) {
+			return nil
+		}
+		if t.Kind() != reflect.Pointer && t.Kind() != reflect.Slice {
+			break
+		}
+		t = t.Elem()
+	}
+
+	_, isMemory := memoryUnit(unit)
+	switch {
+	case t == durationType && isMemory:
+		return fmt.Errorf("memory unit %q does not apply to a time.Duration field", unit)
+	case t == durationType:
+		return nil
+	case !isMemory:
+		return fmt.Errorf("time unit %q requires a time.Duration field, got %s", unit, t)
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return nil
+	}
+	return fmt.Errorf("memory unit %q requires an integer field, got %s", unit, t)
+}
+
+// setQuantityField decodes param into fieldValue, a time.Duration or, for a
+// memory unit, an integer field, with unit as the implied unit of a bare
+// number.
+func setQuantityField(fieldValue reflect.Value, param *Param, unit string) error {
+	_, isMemory := memoryUnit(unit)
+	switch {
+	case fieldValue.Type() == durationType:
+		if isMemory {
+			return fmt.Errorf("memory unit %q does not apply to a time.Duration field", unit)
+		}
+		d, err := parseDuration(param.Value, unit)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetInt(int64(d))
+	case !isMemory:
+		return fmt.Errorf("time unit %q requires a time.Duration field, got %s", unit, fieldValue.Type())
+	case fieldValue.CanInt():
+		v, err := parseMemory(param.Value, unit)
+		if err != nil {
+			return err
+		}
+		if !intInBounds(fieldValue.Kind(), v) {
+			return fmt.Errorf("value %q overflows %s", param.Value, fieldValue.Kind())
+		}
+		fieldValue.SetInt(v)
+	case fieldValue.CanUint():
+		v, err := parseMemory(param.Value, unit)
+		if err != nil {
+			return err
+		}
+		if v < 0 {
+			return fmt.Errorf("negative value for unsigned field: %q", param.Value)
+		}
+		if !uintInBounds(fieldValue.Kind(), uint64(v)) {
+			return fmt.Errorf("value %q overflows %s", param.Value, fieldValue.Kind())
+		}
+		fieldValue.SetUint(uint64(v))
+	default:
+		return fmt.Errorf("memory unit %q requires an integer field, got %s", unit, fieldValue.Type())
+	}
+	return nil
+}
+
+// formatQuantityField formats fieldValue, a time.Duration or, for a memory
+// unit, an integer field holding a count of unit.
+func formatQuantityField(fieldValue reflect.Value, unit string) (string, error) {
+	_, isMemory := memoryUnit(unit)
+	switch {
+	case fieldValue.Type() == durationType:
+		if isMemory {
+			return "", fmt.Errorf("memory unit %q does not apply to a time.Duration field", unit)
+		}
+		return formatDuration(time.Duration(fieldValue.Int())), nil
+	case !isMemory:
+		return "", fmt.Errorf("time unit %q requires a time.Duration field, got %s", unit, fieldValue.Type())
+	case fieldValue.CanInt():
+		v := fieldValue.Int()
+		if v < 0 {
+			return "-" + formatMemory(uint64(-v), unit), nil
+		}
+		return formatMemory(uint64(v), unit), nil
+	case fieldValue.CanUint():
+		return formatMemory(fieldValue.Uint(), unit), nil
+	default:
+		return "", fmt.Errorf("memory unit %q requires an integer field, got %s", unit, fieldValue.Type())
+	}
+}
+
+// splitQuantity splits raw into its numeric prefix and its unit, with
+// surrounding whitespace removed. The number is empty when raw does not start
+// with one.
+func splitQuantity(raw string) (number, unit string) {
+	raw = strings.TrimSpace(raw)
+	i := 0
+	if i < len(raw) && (raw[i] == '+' || raw[i] == '-') {
+		i++
+	}
+	digits := 0
+	for i < len(raw) && (raw[i] >= '0' && raw[i] <= '9' || raw[i] == '.') {
+		i++
+		digits++
+	}
+	if digits == 0 {
+		return "", raw
+	}
+	return raw[:i], strings.TrimSpace(raw[i:])
+}
+
+// parseDuration interprets a string as a time.Duration. It accepts a
+// PostgreSQL quantity such as "30s" or "1.5h", a bare number in
+// implied units, or Go time.ParseDuration syntax such as "1h30m". When
+// impliedUnit is empty, a bare number is decoded by parseInt as nanoseconds.
+func parseDuration(raw, impliedUnit string) (time.Duration, error) {
+	if impliedUnit == "" {
+		if n, err := parseInt(raw); err == nil {
+			return time.Duration(n), nil
+		}
+	}
+	number, unit := splitQuantity(raw)
+	if number == "" {
+		return 0, fmt.Errorf("invalid duration value: %q", raw)
+	}
+	if unit == "" {
+		unit = impliedUnit
+	}
+
+	size, ok := timeUnit(unit)
+	if ok {
+		return scaleDuration(number, size, raw)
+	}
+
+	d, err := time.ParseDuration(strings.TrimSpace(raw))
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration value: %q", raw)
+	}
+	return d, nil
+}
+
+// scaleDuration multiplies number by size, rounding a fraction to the nearest
+// nanosecond. raw is the original value, for error messages.
+func scaleDuration(number string, size time.Duration, raw string) (time.Duration, error) {
+	if n, err := strconv.ParseInt(number, 10, 64); err == nil {
+		if n > math.MaxInt64/int64(size) || n < math.MinInt64/int64(size) {
+			return 0, fmt.Errorf("duration %q overflows time.Duration", raw)
+		}
+		return time.Duration(n) * size, nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration value: %q", raw)
+	}
+	ns := math.Round(f * float64(size))
+	if ns >= math.MaxInt64 || ns < math.MinInt64 {
+		return 0, fmt.Errorf("duration %q overflows time.Duration", raw)
+	}
+	return time.Duration(ns), nil
+}
+
+// formatDuration formats d in the largest PostgreSQL time unit that holds it
+// exactly, such as "90s" or "2d". A duration with a nanosecond remainder uses
+// Go time.Duration syntax, which parseDuration also accepts.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+	for _, u := range timeUnits {
+		if d%u.size == 0 {
+			return fmt.Sprintf("%d%s", d/u.size, u.name)
+		}
+	}
+	return d.String()
+}
+
+// parseMemory interprets a string as a PostgreSQL memory size, such as
+// "128MB" or "1.5 GB", and returns it as a count of unit, rounded to the
+// nearest whole unit. A bare number is already a count of unit.
+func parseMemory(raw, unit string) (int64, error) {
+	unitSize, _ := memoryUnit(unit)
+	number, suffix := splitQuantity(raw)
+	if suffix == "" {
+		suffix = unit
+	}
+	size, ok := memoryUnit(suffix)
+	if number == "" || !ok {
+		return 0, fmt.Errorf("invalid memory size value: %q; units are B, kB, MB, GB, TB", raw)
+	}
+
+	if n, err := strconv.ParseInt(number, 10, 64); err == nil {
+		if size >= unitSize {
+			factor := size / unitSize
+			if n > math.MaxInt64/factor || n < math.MinInt64/factor {
+				return 0, fmt.Errorf("memory size %q overflows int64", raw)
+			}
+			return n * factor, nil
+		}
+		// Round half away from zero, as PostgreSQL does.
+		divisor := unitSize / size
+		if n < 0 {
+			return -((-n + divisor/2) / divisor), nil
+		}
+		return (n + divisor/2) / divisor, nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory size value: %q", raw)
+	}
+	v := math.Round(f * float64(size) / float64(unitSize))
+	if v >= math.MaxInt64 || v < math.MinInt64 {
+		return 0, fmt.Errorf("memory size %q overflows int64", raw)
+	}
+	return int64(v), nil
+}
+
+// formatMemory formats n, a count of unit, in the largest memory unit that
+// holds it exactly, such as "128MB".
+func formatMemory(n uint64, unit string) string {
+	if n == 0 {
+		return "0"
+	}
+	unitSize, _ := memoryUnit(unit)
+	for _, u := range memoryUnits {
+		if u.size < unitSize {
+			break
+		}
+		if factor := uint64(u.size / unitSize); n%factor == 0 {
+			return fmt.Sprintf("%d%s", n/factor, u.name)
+		}
+	}
+	return fmt.Sprintf("%d%s", n, unit)
+}
@@ -0,0 +1,189 @@
+package pgini
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// --- parseDuration tests ---
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		raw  string
+		unit string
+		want time.Duration
+	}{
+		{"30s", "", 30 * time.Second},
+		{"5min", "", 5 * time.Minute},
+		{"1h", "", time.Hour},
+		{"100ms", "", 100 * time.Millisecond},
+		{"2d", "", 48 * time.Hour},
+		{"250us", "", 250 * time.Microsecond},
+		{"30 s", "", 30 * time.Second},
+		{" 1.5h ", "", 90 * time.Minute},
+		{"-1s", "", -time.Second},
+		{"0", "", 0},
+		{"30", "", 30 * time.Nanosecond},
+		{"1h30m", "", 90 * time.Minute},
+		{"1.5µs", "", 1500 * time.Nanosecond},
+		{"300ns", "", 300 * time.Nanosecond},
+		{"500", "ms", 500 * time.Millisecond},
+		{"-1", "ms", -time.Millisecond},
+		{"0.5", "s", 500 * time.Millisecond},
+		{"2min", "ms", 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw+"/"+tt.unit, func(t *testing.T) {
+			got, err := parseDuration(tt.raw, tt.unit)
+			if err != nil {
+				t.Fatalf("parseDuration(%q, %q): %v", tt.raw, tt.unit, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseDuration(%q, %q) = %v, want %v", tt.raw, tt.unit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDuration_Errors(t *testing.T) {
+	tests := []struct {
+		raw     string
+		unit    string
+		wantErr string
+	}{
+		{"", "", "invalid duration"},
+		{"soon", "", "invalid duration"},
+		{"30S", "", "invalid duration"},
+		{"30 fortnights", "", "invalid duration"},
+		{"1.2.3s", "", "invalid duration"},
+		{"200000d", "", "overflows"},
+		{"1e30", "h", "invalid duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			_, err := parseDuration(tt.raw, tt.unit)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseDuration(%q, %q) error = %v, want %q", tt.raw, tt.unit, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// --- formatDuration tests ---
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0"},
+		{48 * time.Hour, "2d"},
+		{25 * time.Hour, "25h"},
+		{90 * time.Minute, "90min"},
+		{90 * time.Second, "90s"},
+		{1500 * time.Millisecond, "1500ms"},
+		{-time.Second, "-1s"},
+		{250 * time.Microsecond, "250us"},
+		{1500 * time.Nanosecond, "1.5µs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got := formatDuration(tt.d)
+			if got != tt.want {
+				t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+			}
+			back, err := parseDuration(got, "")
+			if err != nil || back != tt.d {
+				t.Errorf("parseDuration(%q) = %v, %v; want %v", got, back, err, tt.d)
+			}
+		})
+	}
+}
+
+// --- parseMemory tests ---
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		raw  string
+		unit string
+		want int64
+	}{
+		{"128MB", "B", 128 << 20},
+		{"4MB", "kB", 4096},
+		{"1GB", "MB", 1024},
+		{"2TB", "GB", 2048},
+		{"1 kB", "B", 1024},
+		{"1.5GB", "MB", 1536},
+		{"1536B", "kB", 2},
+		{"1535B", "kB", 1},
+		{"-1", "kB", -1},
+		{"64", "kB", 64},
+		{"0", "MB", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw+"/"+tt.unit, func(t *testing.T) {
+			got, err := parseMemory(tt.raw, tt.unit)
+			if err != nil {
+				t.Fatalf("parseMemory(%q, %q): %v", tt.raw, tt.unit, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseMemory(%q, %q) = %d, want %d", tt.raw, tt.unit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMemory_Errors(t *testing.T) {
+	tests := []struct {
+		raw     string
+		wantErr string
+	}{
+		{"", "invalid memory size"},
+		{"lots", "invalid memory size"},
+		{"4mb", "invalid memory size"},
+		{"4KB", "invalid memory size"},
+		{"4 MiB", "invalid memory size"},
+		{"1.2.3MB", "invalid memory size"},
+		{"9000000TB", "overflows"},
+		{"1e30TB", "invalid memory size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			_, err := parseMemory(tt.raw, "B")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseMemory(%q) error = %v, want %q", tt.raw, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// --- formatMemory tests ---
+
+func TestFormatMemory(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		unit string
+		want string
+	}{
+		{0, "B", "0"},
+		{128 << 20, "B", "128MB"},
+		{1536, "B", "1536B"},
+		{4096, "kB", "4MB"},
+		{1000, "kB", "1000kB"},
+		{3 << 30, "kB", "3TB"},
+		{2048, "GB", "2TB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got := formatMemory(tt.n, tt.unit)
+			if got != tt.want {
+				t.Errorf("formatMemory(%d, %q) = %q, want %q", tt.n, tt.unit, got, tt.want)
+			}
+			back, err := parseMemory(got, tt.unit)
+			if err != nil || uint64(back) != tt.n {
+				t.Errorf("parseMemory(%q, %q) = %d, %v; want %d", got, tt.unit, back, err, tt.n)
+			}
+		})
+	}
+}
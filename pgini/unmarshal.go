@@ -1,90 +1,668 @@
 // Unmarshaling decodes IniFile instances into Go structs using struct field tags.
 //
-// Fields are mapped via `ini:"KEY"` tags. Fields without an `ini` tag or with
-// an empty tag value are skipped. For primitive types (string, bool, int*,
-// uint*, float*), a default parser is used. For other types, a custom
-// Unmarshal<FieldName> method must exist on the struct.
+// Fields are mapped via `ini:"KEY"` tags; see tags.go for tag options. Fields
+// without an `ini` tag or with an empty tag value are skipped. A custom
+// Unmarshal<FieldName> method on the struct takes precedence; then a field
+// type implementing IniUnmarshaler or encoding.TextUnmarshaler decodes
+// itself. time.Duration fields accept PostgreSQL time units, and integer
+// fields tagged with a memory unit accept memory units (see units.go). Slice
+// fields decode from a list value (see lists.go). Pointer fields are allocated
+// only when their key is present, so a nil pointer means the key is absent.
+// For primitive types (string, bool, int*, uint*, float*), a default parser is
+// used.
 
 package pgini
 
 import (
+	"encoding"
+	"errors"
 	"fmt"
+	"io/fs"
 	"math"
+	"os"
+	"path"
 	"reflect"
 	"strconv"
 	"strings"
 )
 
+// IniUnmarshaler is implemented by types that decode themselves from a PGINI
+// parameter. It takes precedence over encoding.TextUnmarshaler, and gives
+// access to the whole Param, including its Origin.
+type IniUnmarshaler interface {
+	UnmarshalIniParam(p *Param) error
+}
+
+// UnmarshalOption configures optional decoding behavior.
+type UnmarshalOption func(*unmarshalOptions)
+
+// unmarshalOptions holds the settings applied by UnmarshalOption functions.
+type unmarshalOptions struct {
+	// strict rejects parameters and sections that no field consumes.
+	strict bool
+	// defaultsOnly skips required keys, validation, Validator, and `env` tags,
+	// for Defaults.
+	defaultsOnly bool
+	// environ holds the variables given to WithEnviron, or is nil to look
+	// `env` tag variables up in the process environment.
+	environ map[string]string
+	// fsys is the filesystem of the IniFile being decoded, within which
+	// path_exists resolves paths the file set; nil for the OS.
+	fsys fs.FS
+	// parseOpts are the ParseOptions given to WithParseOptions, for the
+	// Load functions.
+	parseOpts []ParseOption
+}
+
+// WithStrict makes decoding report every parameter that no field consumes,
+// with its location, so that misspelled keys do not go unnoticed. UnmarshalFile
+// also reports sections that no section field consumes.
+func WithStrict() UnmarshalOption {
+	return func(o *unmarshalOptions) {
+		o.strict = true
+	}
+}
+
+// WithEnviron makes `env:"NAME"` struct tags look their variables up in
+// environ, a list of "NAME=VALUE" entries as returned by os.Environ, instead of
+// the process environment. Later entries override earlier ones.
+func WithEnviron(environ []string) UnmarshalOption {
+	vars := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		if name, value, ok := strings.Cut(entry, "="); ok {
+			vars[name] = value
+		}
+	}
+	return func(o *unmarshalOptions) {
+		o.environ = vars
+	}
+}
+
+// WithParseOptions passes opts to the parser when a Load function reads the
+// file, as in WithParseOptions(WithBaseDir(dir)) for LoadReader. IniFile
+// methods ignore it, since their file is already parsed.
+func WithParseOptions(opts ...ParseOption) UnmarshalOption {
+	return func(o *unmarshalOptions) {
+		o.parseOpts = append(o.parseOpts, opts...)
+	}
+}
+
+// lookupEnv returns the value of the environment variable name and whether
+// it is set, from the environment given to WithEnviron, if any.
+func (o *unmarshalOptions) lookupEnv(name string) (string, bool) {
+	if o.environ != nil {
+		value, ok := o.environ[name]
+		return value, ok
+	}
+	return os.LookupEnv(name)
+}
+
+// newUnmarshalOptions applies opts to the default settings.
+func newUnmarshalOptions(opts []UnmarshalOption) *unmarshalOptions {
+	o := &unmarshalOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
 // UnmarshalSection decodes the named section's parameters into the exported
 // fields of structPtr. structPtr must be a pointer to a struct. Fields are matched
 // by their `ini:"KEY"` tag. Fields without an `ini` tag or with an empty tag value
-// are skipped. Parameters that do not match any field are ignored.
-func (f *IniFile) UnmarshalSection(name string, structPtr any) error {
-	// Unwrap the pointer to get the underlying struct value and its type descriptor.
+// are skipped, as are section fields. A field whose key is missing is set from
+// its `default:"VALUE"` tag, if any, is an error if tagged `required`, and is
+// otherwise left unchanged. A field tagged `env:"NAME"` is instead decoded from
+// the environment variable NAME when it is set (see WithEnviron), with an
+// Origin naming the variable. Parameters that do not match any field go to the
+// `ini:",remain"` field, if any, and are otherwise ignored, unless WithStrict
+// is given.
+//
+// structPtr may instead be a pointer to a map[string]string or map[string]any,
+// which receives every parameter of the section, keyed by its lowercased key.
+// A nil map is allocated, and existing entries are kept unless overwritten.
+//
+// Decoding continues past a field that fails; the returned error wraps a
+// DecodeErrors listing every failure.
+func (f *IniFile) UnmarshalSection(name string, structPtr any, opts ...UnmarshalOption) error {
+	// Unwrap the pointer to get the underlying struct, or map, value.
 	structValue := reflect.ValueOf(structPtr)
-	if structValue.Kind() != reflect.Pointer || structValue.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("UnmarshalSection: data must be a pointer to a struct, got %T", structPtr)
+	if !isTarget(structValue) {
+		return fmt.Errorf("UnmarshalSection: data must be a pointer to a struct or to a map[string]string or map[string]any, got %T", structPtr)
 	}
 	structValue = structValue.Elem()
-	structType := structValue.Type()
 
 	section := f.GetSection(name)
 	if section == nil {
 		return fmt.Errorf("UnmarshalSection: section %q not found", name)
 	}
+	if structValue.Kind() == reflect.Map {
+		var params []*Param
+		for _, p := range section.Params() {
+			params = append(params, p)
+		}
+		unmarshalMap(structValue, params)
+		return nil
+	}
+
+	o := newUnmarshalOptions(opts)
+	o.fsys = f.fsys
+	if err := unmarshalStruct(section, structValue, o); err != nil {
+		return fmt.Errorf("UnmarshalSection: %w", err)
+	}
+	return nil
+}
+
+// isTarget reports whether v, the target of UnmarshalSection or
+// MarshalSection, is a non-nil pointer to a struct or to a remain map.
+func isTarget(v reflect.Value) bool {
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return false
+	}
+	return v.Elem().Kind() == reflect.Struct || isRemainMap(v.Type().Elem())
+}
+
+// UnmarshalFile decodes the whole file into the exported fields of structPtr.
+// structPtr must be a pointer to a struct. Fields tagged `ini:"NAME,section"`
+// must be structs, and are decoded from the [NAME] section as by
+// UnmarshalSection; a missing section sets only the field's defaults. All
+// other tagged fields are decoded from the default section. With WithStrict,
+// sections that no section field consumes are reported too. As with
+// UnmarshalSection, the returned error wraps a DecodeErrors.
+func (f *IniFile) UnmarshalFile(structPtr any, opts ...UnmarshalOption) error {
+	// Unwrap the pointer to get the underlying struct value and its type descriptor.
+	structValue := reflect.ValueOf(structPtr)
+	if structValue.Kind() != reflect.Pointer || structValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("UnmarshalFile: data must be a pointer to a struct, got %T", structPtr)
+	}
+
+	o := newUnmarshalOptions(opts)
+	o.fsys = f.fsys
+	if err := unmarshalFile(f, structValue.Elem(), o); err != nil {
+		return fmt.Errorf("UnmarshalFile: %w", err)
+	}
+	return nil
+}
 
-	// Iterate over each exported struct field, looking for `ini` tags.
-	for i := range structType.NumField() {
-		fieldDef := structType.Field(i) // field metadata (name, type, tags)
-		tag, ok := fieldDef.Tag.Lookup("ini")
-		if !ok || tag == "" {
+// Defaults returns a new T with every field that has a `default:"VALUE"` tag
+// set from it, as UnmarshalFile would for an empty file. Required fields
+// without a default are left at their zero value. T must be a struct.
+func Defaults[T any]() (*T, error) {
+	if reflect.TypeFor[T]().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Defaults: type must be a struct, got %s", reflect.TypeFor[T]())
+	}
+	f, err := NewIniFile("defaults.conf")
+	if err != nil {
+		return nil, fmt.Errorf("Defaults: %w", err)
+	}
+
+	t := new(T)
+	if err := unmarshalFile(f, reflect.ValueOf(t).Elem(), &unmarshalOptions{defaultsOnly: true}); err != nil {
+		return nil, fmt.Errorf("Defaults: %w", err)
+	}
+	return t, nil
+}
+
+// unmarshalFile decodes f into structValue, a settable struct, as described
+// by UnmarshalFile. Errors from the default section and each section field
+// are collected into one DecodeErrors.
+func unmarshalFile(f *IniFile, structValue reflect.Value, o *unmarshalOptions) error {
+	fields, err := taggedFields(structValue.Type())
+	if err != nil {
+		return err
+	}
+
+	var errs DecodeErrors
+	if err := errs.add(unmarshalSection(sectionOrEmpty(f, ""), structValue, o)); err != nil {
+		return err
+	}
+
+	consumed := map[string]bool{"": true} // section names decoded by some field
+	for _, field := range fields {
+		if !field.tag.isSection() {
 			continue
 		}
+		_, fieldValue, _ := fieldAt(structValue, field, true)
+		switch {
+		case field.tag.sections:
+			sections, err := matchSections(f, []string{field.tag.name})
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field.name, err)
+			}
+			for _, section := range sections {
+				consumed[section.Name] = true
+			}
+			if err := errs.add(unmarshalSectionsField(f, field, fieldValue, o)); err != nil {
+				return err
+			}
+		case field.tag.section:
+			if fieldValue.Kind() != reflect.Struct {
+				return fmt.Errorf("field %s: section field must be a struct, got %s", field.name, fieldValue.Type())
+			}
+
+			section := sectionOrEmpty(f, field.tag.name)
+			consumed[section.Name] = true
+			if err := errs.add(unmarshalStruct(section, fieldValue, o)); err != nil {
+				return fmt.Errorf("field %s: %w", field.name, err)
+			}
+		}
+	}
 
-		param, found := section.GetParam(tag)
-		if !found {
+	if o.strict {
+		for _, section := range f.Sections() {
+			if !consumed[section.Name] {
+				errs = append(errs, &DecodeError{
+					Section: section.Name,
+					Origin:  firstOrigin(section.Origins),
+					Err:     errors.New("unknown section"),
+				})
+			}
+		}
+	}
+	if len(errs) == 0 && !o.defaultsOnly {
+		return validateStruct("", structValue)
+	}
+	return errs.err()
+}
+
+// sectionOrEmpty returns the named section of f, or an empty section with
+// that name if f has none, so that defaults still apply.
+func sectionOrEmpty(f *IniFile, name string) *Section {
+	if section := f.GetSection(name); section != nil {
+		return section
+	}
+	return &Section{Name: strings.ToLower(name), params: make(map[string]*Param)}
+}
+
+// UnmarshalSections decodes each named section of f whose name matches any of
+// patterns into a new T, keyed by section name. Patterns use path.Match
+// syntax, such as "db_*"; with no patterns, every named section matches. The
+// default section is never included. T must be a struct with `ini:"KEY"`
+// field tags, decoded as by UnmarshalSection.
+//
+// Errors from individual sections are collected into one DecodeErrors, which
+// is returned together with the sections that decoded cleanly.
+func UnmarshalSections[T any](f *IniFile, patterns ...string) (map[string]*T, error) {
+	if f == nil {
+		return nil, fmt.Errorf("UnmarshalSections: IniFile is nil")
+	}
+	if reflect.TypeFor[T]().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("UnmarshalSections: type must be a struct, got %s", reflect.TypeFor[T]())
+	}
+	sections, err := matchSections(f, patterns)
+	if err != nil {
+		return nil, fmt.Errorf("UnmarshalSections: %w", err)
+	}
+
+	out := make(map[string]*T, len(sections))
+	var errs DecodeErrors
+	for _, section := range sections {
+		t := new(T)
+		err := unmarshalStruct(section, reflect.ValueOf(t).Elem(), &unmarshalOptions{fsys: f.fsys})
+		if err == nil {
+			out[section.Name] = t
 			continue
 		}
+		if err := errs.add(err); err != nil {
+			return nil, fmt.Errorf("UnmarshalSections: %w", err)
+		}
+	}
+	if len(errs) > 0 {
+		return out, fmt.Errorf("UnmarshalSections: %w", errs)
+	}
+	return out, nil
+}
+
+// unmarshalSectionsField decodes every section matching the pattern of a
+// sections field into fieldValue, a map[string]S or map[string]*S where S is
+// a struct. Existing entries are decoded onto; errors from individual
+// sections are collected into one DecodeErrors.
+func unmarshalSectionsField(f *IniFile, field taggedField, fieldValue reflect.Value, o *unmarshalOptions) error {
+	elemType, err := sectionsElemType(field)
+	if err != nil {
+		return err
+	}
+	sections, err := matchSections(f, []string{field.tag.name})
+	if err != nil {
+		return fmt.Errorf("field %s: %w", field.name, err)
+	}
 
-		fieldValue := structValue.Field(i) // the runtime value of this field
-		if err := unmarshalField(structValue, fieldDef, fieldValue, param); err != nil {
-			return fmt.Errorf("UnmarshalSection: field %s: %w", fieldDef.Name, err)
+	if fieldValue.IsNil() {
+		fieldValue.Set(reflect.MakeMapWithSize(fieldValue.Type(), len(sections)))
+	}
+	var errs DecodeErrors
+	for _, section := range sections {
+		key := reflect.ValueOf(section.Name)
+
+		// Decode into a copy of any existing entry, so defaults survive.
+		elem := reflect.New(elemType)
+		if existing := fieldValue.MapIndex(key); existing.IsValid() {
+			if existing.Kind() != reflect.Pointer {
+				elem.Elem().Set(existing)
+			} else if !existing.IsNil() {
+				elem = existing
+			}
+		}
+		if err := unmarshalStruct(section, elem.Elem(), o); err != nil {
+			if err := errs.add(err); err != nil {
+				return fmt.Errorf("field %s: %w", field.name, err)
+			}
+			continue
+		}
+
+		if fieldValue.Type().Elem().Kind() == reflect.Pointer {
+			fieldValue.SetMapIndex(key, elem)
+		} else {
+			fieldValue.SetMapIndex(key, elem.Elem())
 		}
 	}
+	return errs.err()
+}
 
-	return nil
+// sectionsElemType returns the struct type S of a sections field, which must
+// be a map[string]S or map[string]*S.
+func sectionsElemType(field taggedField) (reflect.Type, error) {
+	t := field.def.Type
+	if t.Kind() == reflect.Map && t.Key().Kind() == reflect.String {
+		elem := t.Elem()
+		if elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Struct {
+			return elem, nil
+		}
+	}
+	return nil, fmt.Errorf("field %s: sections field must be a map[string] of struct or struct pointer, got %s", field.name, t)
+}
+
+// matchSections returns the named sections of f, in order, whose names match
+// any of patterns. With no patterns, every named section matches.
+func matchSections(f *IniFile, patterns []string) ([]*Section, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid section pattern %q: %w", pattern, err)
+		}
+	}
+
+	var sections []*Section
+	for _, section := range f.Sections() {
+		if section.Name == "" {
+			continue
+		}
+		matched := len(patterns) == 0
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(strings.ToLower(pattern), section.Name); ok {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			sections = append(sections, section)
+		}
+	}
+	return sections, nil
+}
+
+// unmarshalStruct decodes section into structValue, a settable struct, as
+// unmarshalSection does, and then calls its Validate method if it implements
+// Validator and decoded without errors.
+func unmarshalStruct(section *Section, structValue reflect.Value, o *unmarshalOptions) error {
+	if err := unmarshalSection(section, structValue, o); err != nil {
+		return err
+	}
+	if o.defaultsOnly {
+		return nil
+	}
+	return validateStruct(section.Name, structValue)
+}
+
+// unmarshalSection decodes the parameters of section into the key fields of
+// structValue, a settable struct, or from the environment variable of a
+// field's `env` tag when it is set. A field whose key is missing is set from its
+// `default:"VALUE"` tag, if any, and is an error if tagged `required`. Each
+// decoded field is then checked against its `validate` tag. Section fields are
+// skipped. Parameters that no field consumes go to the remain field, if any,
+// and are otherwise errors in strict mode. Errors are collected into one DecodeErrors, in field order and then
+// parameter order.
+func unmarshalSection(section *Section, structValue reflect.Value, o *unmarshalOptions) error {
+	fields, err := taggedFields(structValue.Type())
+	if err != nil {
+		return err
+	}
+
+	// Allocate the nil inline struct pointers that hold a key of the section
+	// first, so that their other fields get defaults too. An inline struct
+	// pointer without keys stays nil, and its fields are skipped.
+	for _, field := range fields {
+		if _, found := lookupParam(section, field, o); found && !field.tag.isSection() {
+			fieldAt(structValue, field, true)
+		}
+	}
+
+	var errs DecodeErrors
+	consumed := make(map[string]bool) // parameter keys decoded by some field
+	for _, field := range fields {
+		if field.tag.isSection() || field.tag.remain {
+			continue
+		}
+		consumed[strings.ToLower(field.tag.name)] = true
+		// The runtime value of this field, and the struct with its custom methods.
+		receiver, fieldValue, ok := fieldAt(structValue, field, false)
+		if !ok {
+			continue
+		}
+		param, found := lookupParam(section, field, o)
+		switch {
+		case found:
+			if err := unmarshalField(receiver, field, fieldValue, param); err != nil {
+				errs = append(errs, fieldError(section, field, param, err))
+				continue
+			}
+		case field.tag.hasDefault:
+			// A default decodes exactly as if the file had set it.
+			param = &Param{Name: field.tag.name, Value: field.tag.defaultValue}
+			if err := unmarshalField(receiver, field, fieldValue, param); err != nil {
+				errs = append(errs, fieldError(section, field, param, fmt.Errorf("default %q: %w", param.Value, err)))
+				continue
+			}
+		case field.tag.required && !o.defaultsOnly:
+			errs = append(errs, fieldError(section, field, nil, errors.New("required key is missing")))
+			continue
+		default:
+			param = nil
+		}
+
+		if len(field.rules) > 0 && !o.defaultsOnly {
+			if err := validateField(field, fieldValue, param, o.fsys); err != nil {
+				errs = append(errs, fieldError(section, field, param, err))
+			}
+		}
+	}
+
+	var unclaimed []*Param // parameters that no field consumes
+	for _, p := range section.Params() {
+		if !consumed[p.Name] {
+			unclaimed = append(unclaimed, p)
+		}
+	}
+	remain, hasRemain := remainField(fields)
+	switch {
+	case hasRemain && len(unclaimed) > 0:
+		_, fieldValue, _ := fieldAt(structValue, remain, true)
+		unmarshalMap(fieldValue, unclaimed)
+	case !hasRemain && o.strict:
+		for _, p := range unclaimed {
+			errs = append(errs, &DecodeError{
+				Section: section.Name,
+				Key:     p.Name,
+				Value:   p.Value,
+				Origin:  p.Origin,
+				Err:     errors.New("unknown key"),
+			})
+		}
+	}
+	return errs.err()
+}
+
+// unmarshalMap sets an entry of mapValue, a settable map[string]string or
+// map[string]any, for each of params, keyed by its lowercased key. A nil map
+// is allocated; existing entries are kept unless overwritten.
+func unmarshalMap(mapValue reflect.Value, params []*Param) {
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMapWithSize(mapValue.Type(), len(params)))
+	}
+	keyType, elemType := mapValue.Type().Key(), mapValue.Type().Elem()
+	for _, p := range params {
+		mapValue.SetMapIndex(reflect.ValueOf(p.Name).Convert(keyType), reflect.ValueOf(p.Value).Convert(elemType))
+	}
+}
+
+// lookupParam returns the definition that field decodes from: the
+// environment variable of its `env` tag when it is set, or else the section's
+// definition of its key. It reports whether either was found.
+func lookupParam(section *Section, field taggedField, o *unmarshalOptions) (*Param, bool) {
+	if field.tag.env != "" && !o.defaultsOnly {
+		if value, ok := o.lookupEnv(field.tag.env); ok {
+			return &Param{Name: strings.ToLower(field.tag.name), Value: value, Origin: Location{Env: field.tag.env}}, true
+		}
+	}
+	return section.GetParam(field.tag.name)
+}
+
+// fieldError returns a DecodeError for field in section. param is the
+// definition that failed, or nil when the key is missing.
+func fieldError(section *Section, field taggedField, param *Param, err error) *DecodeError {
+	e := &DecodeError{
+		Section: section.Name,
+		Key:     strings.ToLower(field.tag.name),
+		Field:   field.name,
+		Type:    field.def.Type,
+		Err:     err,
+	}
+	if param != nil {
+		e.Value = param.Value
+		e.Origin = param.Origin
+	}
+	return e
 }
 
-// unmarshalField sets a struct field from a Param value. If a custom
-// Unmarshal<FieldName> method exists on the struct, it is used. Otherwise,
-// the default setFieldFromParam is used for primitive types.
+// firstOrigin returns the first of origins, or a zero Location.
+func firstOrigin(origins []Location) Location {
+	if len(origins) == 0 {
+		return Location{}
+	}
+	return origins[0]
+}
+
+// unmarshalField sets a struct field from a Param value, using the first of:
+//  1. a custom Unmarshal<FieldName> method on the struct that declares the field (see fieldAt)
+//  2. the field type's IniUnmarshaler implementation
+//  3. the field type's encoding.TextUnmarshaler implementation
+//  4. setPointerField for pointers, decoding the pointee by steps 2 to 7
+//  5. setSliceField for slices, decoding each element by steps 2 to 7
+//  6. setQuantityField for time.Duration, or an integer with a memory unit
+//  7. setFieldFromParam for primitive types
 //
 // Parameters:
-//   - structValue: the reflect.Value of the dereferenced struct instance
-//   - fieldDef: metadata for the struct field (name, type, tags)
+//   - structValue: the reflect.Value of the dereferenced struct with the custom methods
+//   - field: the struct field's metadata and parsed `ini` tag
 //   - fieldValue: the runtime value of the struct field to populate
 //   - param: the INI parameter whose value will be decoded into fieldValue
-func unmarshalField(structValue reflect.Value, fieldDef reflect.StructField, fieldValue reflect.Value, param *Param) error {
-	// Look for a custom unmarshal method named Unmarshal<FieldName> on the struct's pointer receiver.
-	methodName := "Unmarshal" + fieldDef.Name
-	method := structValue.Addr().MethodByName(methodName)
-	if method.IsValid() {
-		return callCustomUnmarshal(method, fieldDef, fieldValue, param)
+func unmarshalField(structValue reflect.Value, field taggedField, fieldValue reflect.Value, param *Param) error {
+	// A custom Unmarshal<FieldName> method, resolved when the struct's plan was compiled.
+	if m := field.unmarshalMethod; m != nil {
+		if m.err != nil {
+			return m.err
+		}
+		return callCustomUnmarshal(structValue.Addr().Method(m.index), field.def, fieldValue, param)
+	}
+	return unmarshalValue(fieldValue, param, field.tag)
+}
+
+// unmarshalValue decodes param into v, a field or slice element, using the first of
+// steps 2 to 7 of unmarshalField. tag holds the field's options.
+func unmarshalValue(v reflect.Value, param *Param, tag fieldTag) error {
+	if ok, err := unmarshalInterface(v, param); ok {
+		return err
+	}
+	if v.Kind() == reflect.Pointer {
+		return setPointerField(v, param, tag)
+	}
+	if v.Kind() != reflect.Slice && (tag.sep != 0 || tag.multi) {
+		return fmt.Errorf("sep and multi options require a slice field, got %s", v.Type())
 	}
-	return setFieldFromParam(fieldValue, param)
+	switch {
+	case v.Kind() == reflect.Slice:
+		return setSliceField(v, param, tag)
+	case v.Type() == durationType || tag.unit != "":
+		return setQuantityField(v, param, tag.unit)
+	}
+	return setFieldFromParam(v, param)
 }
 
-// callCustomUnmarshal invokes a custom Unmarshal<FieldName> method and validates
-// its signature: func(s *StructType) Unmarshal<FieldName>(value string) (*FieldType, error).
+// setPointerField decodes param into a new value for fieldValue, a pointer,
+// starting from a copy of the current pointee if any. The pointer is set only
+// when decoding succeeds.
+func setPointerField(fieldValue reflect.Value, param *Param, tag fieldTag) error {
+	ptr := reflect.New(fieldValue.Type().Elem())
+	if !fieldValue.IsNil() {
+		ptr.Elem().Set(fieldValue.Elem())
+	}
+	if err := unmarshalValue(ptr.Elem(), param, tag); err != nil {
+		return err
+	}
+	fieldValue.Set(ptr)
+	return nil
+}
+
+// unmarshalInterface decodes param into fieldValue through its IniUnmarshaler
+// or encoding.TextUnmarshaler implementation, preferring IniUnmarshaler. A
+// pointer field decodes into a new value, starting from a copy of the current
+// pointee if any, and is set only when decoding succeeds. It reports whether
+// the field type implements either interface.
+func unmarshalInterface(fieldValue reflect.Value, param *Param) (bool, error) {
+	switch {
+	case fieldValue.Kind() == reflect.Pointer && implementsUnmarshaler(fieldValue.Type()):
+		ptr := reflect.New(fieldValue.Type().Elem())
+		if !fieldValue.IsNil() {
+			ptr.Elem().Set(fieldValue.Elem())
+		}
+		if err := callUnmarshaler(ptr, param); err != nil {
+			return true, err
+		}
+		fieldValue.Set(ptr)
+		return true, nil
+	case fieldValue.CanAddr() && implementsUnmarshaler(reflect.PointerTo(fieldValue.Type())):
+		return true, callUnmarshaler(fieldValue.Addr(), param)
+	}
+	return false, nil
+}
+
+// callUnmarshaler decodes param through target, a pointer whose type
+// implements IniUnmarshaler or encoding.TextUnmarshaler.
+func callUnmarshaler(target reflect.Value, param *Param) error {
+	switch u := target.Interface().(type) {
+	case IniUnmarshaler:
+		return u.UnmarshalIniParam(param)
+	case encoding.TextUnmarshaler:
+		return u.UnmarshalText([]byte(param.Value))
+	}
+	return nil
+}
+
+// implementsUnmarshaler reports whether t implements IniUnmarshaler or
+// encoding.TextUnmarshaler.
+func implementsUnmarshaler(t reflect.Type) bool {
+	return t.Implements(reflect.TypeFor[IniUnmarshaler]()) ||
+		t.Implements(reflect.TypeFor[encoding.TextUnmarshaler]())
+}
+
+// checkUnmarshalMethod validates the signature of a custom Unmarshal<FieldName>
+// method: func(s *StructType) Unmarshal<FieldName>(value string) (*FieldType, error).
 //
 // Parameters:
-//   - method: the reflected method value (already resolved via MethodByName)
+//   - methodSig: the method's function signature, without the receiver
 //   - fieldDef: metadata for the struct field (used to derive expected return type)
-//   - fieldValue: the runtime value of the struct field to populate with the result
-//   - param: the INI parameter whose string value is passed to the method
-func callCustomUnmarshal(method reflect.Value, fieldDef reflect.StructField, fieldValue reflect.Value, param *Param) error {
-	methodSig := method.Type() // the method's function signature
-
+func checkUnmarshalMethod(methodSig reflect.Type, fieldDef reflect.StructField) error {
 	// Validate the method signature: exactly 1 string input, 2 outputs (*FieldType, error).
 	if methodSig.NumIn() != 1 {
 		return fmt.Errorf("Unmarshal%s: expected 1 parameter, got %d", fieldDef.Name, methodSig.NumIn())
@@ -104,7 +682,18 @@ func callCustomUnmarshal(method reflect.Value, fieldDef reflect.StructField, fie
 	if methodSig.Out(0) != expectedReturnType {
 		return fmt.Errorf("Unmarshal%s: first return value must be %s, got %s", fieldDef.Name, expectedReturnType, methodSig.Out(0))
 	}
+	return nil
+}
 
+// callCustomUnmarshal invokes a custom Unmarshal<FieldName> method whose
+// signature checkUnmarshalMethod has validated.
+//
+// Parameters:
+//   - method: the reflected method value, bound to the struct's pointer
+//   - fieldDef: metadata for the struct field
+//   - fieldValue: the runtime value of the struct field to populate with the result
+//   - param: the INI parameter whose string value is passed to the method
+func callCustomUnmarshal(method reflect.Value, fieldDef reflect.StructField, fieldValue reflect.Value, param *Param) error {
 	results := method.Call([]reflect.Value{reflect.ValueOf(param.Value)})
 	if !results[1].IsNil() {
 		return results[1].Interface().(error)
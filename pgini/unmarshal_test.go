@@ -2,9 +2,16 @@ package pgini
 
 import (
 	"fmt"
+	"log/slog"
 	"math"
+	"math/big"
+	"net"
+	"net/netip"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 // --- test helper types for unmarshal ---
@@ -71,6 +78,63 @@ func (s *unmarshalCustomOverride) UnmarshalPort(value string) (*int, error) {
 	return &v, nil
 }
 
+type fileDatabase struct {
+	Host string `ini:"host"`
+	Port int    `ini:"port"`
+}
+
+type fileConfig struct {
+	Name     string       `ini:"name"`
+	Debug    bool         `ini:"debug"`
+	Database fileDatabase `ini:"database,section"`
+	Cache    fileDatabase `ini:"cache,section"`
+}
+
+// color implements IniUnmarshaler and encoding.TextUnmarshaler; the former
+// must win.
+type color struct {
+	name string
+	line int
+}
+
+func (c *color) UnmarshalIniParam(p *Param) error {
+	if p.Value == "" {
+		return fmt.Errorf("empty color")
+	}
+	c.name = "ini:" + p.Value
+	c.line = p.Origin.Line
+	return nil
+}
+
+func (c *color) UnmarshalText(text []byte) error {
+	c.name = "text:" + string(text)
+	return nil
+}
+
+func (c color) MarshalIniParam(p *Param) error {
+	p.Value = strings.TrimPrefix(c.name, "ini:") + "_" + p.Name
+	return nil
+}
+
+func (c color) MarshalText() ([]byte, error) {
+	return []byte("text"), nil
+}
+
+type unmarshalInterfaces struct {
+	Addr    netip.Addr `ini:"addr"`
+	IP      net.IP     `ini:"ip"`
+	Big     big.Int    `ini:"big"`
+	BigPtr  *big.Int   `ini:"big_ptr"`
+	Level   slog.Level `ini:"level"`
+	Color   color      `ini:"color"`
+	Custom  color      `ini:"custom"`
+	Ignored string
+}
+
+func (s *unmarshalInterfaces) UnmarshalCustom(value string) (*color, error) {
+	return &color{name: "method:" + value}, nil
+}
+
 // --- UnmarshalSection tests ---
 
 func TestUnmarshalSection(t *testing.T) {
@@ -299,6 +363,1048 @@ func TestUnmarshalSection(t *testing.T) {
 	})
 }
 
+// --- UnmarshalFile tests ---
+
+func TestUnmarshalFile(t *testing.T) {
+	newFile := func(t *testing.T) *IniFile {
+		t.Helper()
+		f, err := NewIniFile(nonExistingPath("test.conf"))
+		if err != nil {
+			t.Fatalf("NewIniFile: %v", err)
+		}
+		f.GetSection("").SetParam("name", "app")
+		f.GetSection("").SetParam("debug", "on")
+		f.GetSection("").SetParam("host", "not-a-database-host")
+		db, _ := f.AddSection("database")
+		db.SetParam("host", "db.internal")
+		db.SetParam("port", "6543")
+		return f
+	}
+
+	t.Run("default and named sections", func(t *testing.T) {
+		v := &fileConfig{Cache: fileDatabase{Host: "unchanged"}}
+		if err := newFile(t).UnmarshalFile(v); err != nil {
+			t.Fatalf("UnmarshalFile: %v", err)
+		}
+		want := fileConfig{
+			Name:     "app",
+			Debug:    true,
+			Database: fileDatabase{Host: "db.internal", Port: 6543},
+			Cache:    fileDatabase{Host: "unchanged"},
+		}
+		if *v != want {
+			t.Errorf("got %+v, want %+v", *v, want)
+		}
+	})
+
+	t.Run("UnmarshalSection skips section fields", func(t *testing.T) {
+		v := &fileConfig{}
+		if err := newFile(t).UnmarshalSection("", v); err != nil {
+			t.Fatalf("UnmarshalSection: %v", err)
+		}
+		if v.Name != "app" || v.Database != (fileDatabase{}) {
+			t.Errorf("got %+v, want only default-section fields", *v)
+		}
+	})
+
+	t.Run("field error names the section", func(t *testing.T) {
+		f := newFile(t)
+		f.GetSection("database").SetParam("port", "not-a-port")
+		err := f.UnmarshalFile(&fileConfig{})
+		if err == nil || !strings.Contains(err.Error(), `section "database": key "port": field Port`) {
+			t.Errorf("expected section and field in error, got %v", err)
+		}
+	})
+
+	t.Run("non-struct section field errors", func(t *testing.T) {
+		v := &struct {
+			Database string `ini:"database,section"`
+		}{}
+		err := newFile(t).UnmarshalFile(v)
+		if err == nil || !strings.Contains(err.Error(), "must be a struct") {
+			t.Errorf("expected struct error, got %v", err)
+		}
+	})
+
+	t.Run("bad tag errors", func(t *testing.T) {
+		v := &struct {
+			Database fileDatabase `ini:"database,sections"`
+		}{}
+		if err := newFile(t).UnmarshalFile(v); err == nil {
+			t.Error("expected error for unknown tag option")
+		}
+	})
+
+	t.Run("non-pointer errors", func(t *testing.T) {
+		if err := newFile(t).UnmarshalFile(fileConfig{}); err == nil {
+			t.Error("expected error for non-pointer")
+		}
+	})
+}
+
+// --- UnmarshalSections tests ---
+
+// newServiceFile returns an IniFile shaped like pg_service.conf.
+func newServiceFile(t *testing.T) *IniFile {
+	t.Helper()
+	f, err := NewIniFile(nonExistingPath("pg_service.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	f.GetSection("").SetParam("host", "global")
+	for _, name := range []string{"db_main", "db_replica", "cache"} {
+		s, _ := f.AddSection(name)
+		s.SetParam("host", name+".internal")
+		s.SetParam("port", "5432")
+	}
+	return f
+}
+
+func TestUnmarshalSections(t *testing.T) {
+	t.Run("all named sections", func(t *testing.T) {
+		got, err := UnmarshalSections[fileDatabase](newServiceFile(t))
+		if err != nil {
+			t.Fatalf("UnmarshalSections: %v", err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("expected 3 sections, got %d: %v", len(got), got)
+		}
+		if *got["db_main"] != (fileDatabase{Host: "db_main.internal", Port: 5432}) {
+			t.Errorf("db_main = %+v", *got["db_main"])
+		}
+		if _, ok := got[""]; ok {
+			t.Error("default section should not be included")
+		}
+	})
+
+	t.Run("filtered by patterns", func(t *testing.T) {
+		got, err := UnmarshalSections[fileDatabase](newServiceFile(t), "DB_*")
+		if err != nil {
+			t.Fatalf("UnmarshalSections: %v", err)
+		}
+		if len(got) != 2 || got["db_main"] == nil || got["db_replica"] == nil {
+			t.Errorf("expected db_main and db_replica, got %v", got)
+		}
+
+		got, err = UnmarshalSections[fileDatabase](newServiceFile(t), "cache", "db_main")
+		if err != nil {
+			t.Fatalf("UnmarshalSections: %v", err)
+		}
+		if len(got) != 2 || got["cache"] == nil || got["db_main"] == nil {
+			t.Errorf("expected cache and db_main, got %v", got)
+		}
+	})
+
+	t.Run("errors are joined and good sections kept", func(t *testing.T) {
+		f := newServiceFile(t)
+		f.GetSection("db_main").SetParam("port", "five")
+		f.GetSection("cache").SetParam("port", "six")
+		got, err := UnmarshalSections[fileDatabase](f)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		for _, want := range []string{`section "db_main": key "port": field Port`, `section "cache": key "port": field Port`} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q should contain %q", err, want)
+			}
+		}
+		if len(got) != 1 || got["db_replica"] == nil {
+			t.Errorf("expected only db_replica, got %v", got)
+		}
+	})
+
+	t.Run("invalid pattern errors", func(t *testing.T) {
+		if _, err := UnmarshalSections[fileDatabase](newServiceFile(t), "db_["); err == nil {
+			t.Error("expected error for invalid pattern")
+		}
+	})
+
+	t.Run("non-struct type errors", func(t *testing.T) {
+		if _, err := UnmarshalSections[string](newServiceFile(t)); err == nil {
+			t.Error("expected error for non-struct type")
+		}
+	})
+
+	t.Run("nil file errors", func(t *testing.T) {
+		if _, err := UnmarshalSections[fileDatabase](nil); err == nil {
+			t.Error("expected error for nil IniFile")
+		}
+	})
+}
+
+func TestUnmarshalFile_SectionsField(t *testing.T) {
+	t.Run("value and pointer maps", func(t *testing.T) {
+		v := &struct {
+			Host      string                   `ini:"host"`
+			Databases map[string]fileDatabase  `ini:"db_*,sections"`
+			All       map[string]*fileDatabase `ini:"*,sections"`
+		}{}
+		if err := newServiceFile(t).UnmarshalFile(v); err != nil {
+			t.Fatalf("UnmarshalFile: %v", err)
+		}
+		if v.Host != "global" {
+			t.Errorf("Host = %q, want %q", v.Host, "global")
+		}
+		if len(v.Databases) != 2 || v.Databases["db_replica"] != (fileDatabase{Host: "db_replica.internal", Port: 5432}) {
+			t.Errorf("Databases = %+v", v.Databases)
+		}
+		if len(v.All) != 3 || v.All["cache"].Host != "cache.internal" {
+			t.Errorf("All = %+v", v.All)
+		}
+	})
+
+	t.Run("existing entries are decoded onto", func(t *testing.T) {
+		v := &struct {
+			Databases map[string]fileDatabase `ini:"db_main,sections"`
+		}{Databases: map[string]fileDatabase{
+			"db_main":   {Port: 1},
+			"untouched": {Port: 2},
+		}}
+		f := newServiceFile(t)
+		f.GetSection("db_main").RemoveParam("port")
+		if err := f.UnmarshalFile(v); err != nil {
+			t.Fatalf("UnmarshalFile: %v", err)
+		}
+		if v.Databases["db_main"] != (fileDatabase{Host: "db_main.internal", Port: 1}) {
+			t.Errorf("db_main = %+v, want existing port kept", v.Databases["db_main"])
+		}
+		if v.Databases["untouched"].Port != 2 {
+			t.Errorf("untouched entry changed: %+v", v.Databases["untouched"])
+		}
+	})
+
+	t.Run("wrong field type errors", func(t *testing.T) {
+		v := &struct {
+			Databases map[string]string `ini:"db_*,sections"`
+		}{}
+		err := newServiceFile(t).UnmarshalFile(v)
+		if err == nil || !strings.Contains(err.Error(), "sections field must be") {
+			t.Errorf("expected sections type error, got %v", err)
+		}
+	})
+}
+
+// --- IniUnmarshaler and encoding.TextUnmarshaler tests ---
+
+func TestUnmarshalSection_Interfaces(t *testing.T) {
+	f, err := ParseString("interfaces.conf", strings.Join([]string{
+		"addr = '::1'",
+		"ip = 10.0.0.1",
+		"big = 123456789012345678901234567890",
+		"big_ptr = -42",
+		"level = WARN",
+		"color = red",
+		"custom = blue",
+		"",
+	}, "\n"))
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+
+	v := &unmarshalInterfaces{}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if v.Addr != netip.MustParseAddr("::1") {
+		t.Errorf("Addr = %v", v.Addr)
+	}
+	if !v.IP.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("IP = %v", v.IP)
+	}
+	if v.Big.String() != "123456789012345678901234567890" {
+		t.Errorf("Big = %v", &v.Big)
+	}
+	if v.BigPtr == nil || v.BigPtr.Int64() != -42 {
+		t.Errorf("BigPtr = %v", v.BigPtr)
+	}
+	if v.Level != slog.LevelWarn {
+		t.Errorf("Level = %v", v.Level)
+	}
+	if v.Color != (color{name: "ini:red", line: 6}) {
+		t.Errorf("Color = %+v, want IniUnmarshaler with origin line 6", v.Color)
+	}
+	if v.Custom.name != "method:blue" {
+		t.Errorf("Custom = %+v, want Unmarshal<FieldName> method to win", v.Custom)
+	}
+}
+
+func TestUnmarshalSection_InterfaceErrors(t *testing.T) {
+	tests := []struct {
+		param string
+		value string
+		field string
+	}{
+		{"addr", "not-an-ip", "field Addr"},
+		{"ip", "300.0.0.1", "field IP"},
+		{"big", "12x", "field Big"},
+		{"level", "LOUD", "field Level"},
+		{"color", "", "field Color: empty color"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.param, func(t *testing.T) {
+			f, err := NewIniFile(nonExistingPath("test.conf"))
+			if err != nil {
+				t.Fatalf("NewIniFile: %v", err)
+			}
+			f.GetSection("").SetParam(tt.param, tt.value)
+			err = f.UnmarshalSection("", &unmarshalInterfaces{})
+			if err == nil || !strings.Contains(err.Error(), tt.field) {
+				t.Errorf("expected error containing %q, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+// --- time.Duration tests ---
+
+type durations struct {
+	Checkpoint time.Duration `ini:"checkpoint_timeout"`
+	Statement  time.Duration `ini:"statement_timeout,unit=ms"`
+	Idle       time.Duration `ini:"idle_timeout,unit=s"`
+	Retry      time.Duration `ini:"retry"`
+}
+
+func TestUnmarshalSection_Durations(t *testing.T) {
+	f, err := ParseString("durations.conf", strings.Join([]string{
+		"checkpoint_timeout = 5min",
+		"statement_timeout = 1500",
+		"idle_timeout = '2 h'",
+		"retry = 1m30s",
+		"",
+	}, "\n"))
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+
+	v := &durations{}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	want := durations{
+		Checkpoint: 5 * time.Minute,
+		Statement:  1500 * time.Millisecond,
+		Idle:       2 * time.Hour,
+		Retry:      90 * time.Second,
+	}
+	if *v != want {
+		t.Errorf("got %+v, want %+v", *v, want)
+	}
+}
+
+func TestUnmarshalSection_DurationBareNumber(t *testing.T) {
+	// Without a unit tag option, a bare number is nanoseconds, as for an int64.
+	f, err := ParseString("durations.conf", "checkpoint_timeout = 300\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v := &durations{}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if v.Checkpoint != 300*time.Nanosecond {
+		t.Errorf("Checkpoint = %v, want 300ns", v.Checkpoint)
+	}
+}
+
+func TestUnmarshalSection_DurationErrors(t *testing.T) {
+	f, err := ParseString("durations.conf", "checkpoint_timeout = soon\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	err = f.UnmarshalSection("", &durations{})
+	if err == nil || !strings.Contains(err.Error(), "field Checkpoint") || !strings.Contains(err.Error(), "invalid duration") {
+		t.Errorf("expected invalid duration error for field Checkpoint, got %v", err)
+	}
+}
+
+// --- memory unit tests ---
+
+type memorySizes struct {
+	SharedBuffers int64  `ini:"shared_buffers,bytes"`
+	WorkMem       int    `ini:"work_mem,unit=kB"`
+	MaxWal        uint32 `ini:"max_wal_size,unit=MB"`
+	Small         int8   `ini:"small,unit=kB"`
+	Plain         int    `ini:"plain"`
+}
+
+func TestUnmarshalSection_MemorySizes(t *testing.T) {
+	f, err := ParseString("memory.conf", strings.Join([]string{
+		"shared_buffers = 128MB",
+		"work_mem = 4MB",
+		"max_wal_size = '1 GB'",
+		"small = 64",
+		"plain = 0x10",
+		"",
+	}, "\n"))
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+
+	v := &memorySizes{}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	want := memorySizes{SharedBuffers: 128 << 20, WorkMem: 4096, MaxWal: 1024, Small: 64, Plain: 16}
+	if *v != want {
+		t.Errorf("got %+v, want %+v", *v, want)
+	}
+}
+
+func TestUnmarshalSection_MemorySizeErrors(t *testing.T) {
+	tests := []struct {
+		param   string
+		value   string
+		wantErr string
+	}{
+		{"small", "1MB", "field Small: value \"1MB\" overflows int8"},
+		{"max_wal_size", "-1GB", "field MaxWal: negative value"},
+		{"work_mem", "4 megabytes", "field WorkMem: invalid memory size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.param, func(t *testing.T) {
+			f, err := NewIniFile(nonExistingPath("test.conf"))
+			if err != nil {
+				t.Fatalf("NewIniFile: %v", err)
+			}
+			f.GetSection("").SetParam(tt.param, tt.value)
+			err = f.UnmarshalSection("", &memorySizes{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// --- slice tests ---
+
+type lists struct {
+	Hosts    []string        `ini:"hosts"`
+	Ports    []int           `ini:"ports"`
+	Paths    []string        `ini:"paths,sep=:"`
+	Timeouts []time.Duration `ini:"timeouts,unit=s"`
+	Addrs    []netip.Addr    `ini:"addrs"`
+	Allow    []string        `ini:"allow,multi"`
+	Empty    []string        `ini:"empty"`
+}
+
+func TestUnmarshalSection_Slices(t *testing.T) {
+	f, err := ParseString("lists.conf", strings.Join([]string{
+		`hosts = 'db1, "db 2", db3'`,
+		"ports = '5432, 0x1A0B'",
+		"paths = /usr/bin:/bin",
+		"timeouts = '5, 1min'",
+		"addrs = '10.0.0.1, ::1'",
+		"allow = 'a, b'",
+		"allow = c",
+		"empty = ''",
+		"",
+	}, "\n"))
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+
+	v := &lists{Empty: []string{"default"}}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	want := lists{
+		Hosts:    []string{"db1", "db 2", "db3"},
+		Ports:    []int{5432, 0x1A0B},
+		Paths:    []string{"/usr/bin", "/bin"},
+		Timeouts: []time.Duration{5 * time.Second, time.Minute},
+		Addrs:    []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("::1")},
+		Allow:    []string{"a", "b", "c"},
+		Empty:    []string{},
+	}
+	if !reflect.DeepEqual(*v, want) {
+		t.Errorf("got %+v, want %+v", *v, want)
+	}
+}
+
+func TestUnmarshalSection_SliceLastWins(t *testing.T) {
+	f, err := ParseString("lists.conf", "hosts = a\nhosts = 'b, c'\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v := &lists{}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if !slices.Equal(v.Hosts, []string{"b", "c"}) {
+		t.Errorf("Hosts = %q, want last definition only", v.Hosts)
+	}
+}
+
+func TestUnmarshalSection_SliceMultiAcrossIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeTemp(t, dir, "extra.conf", "allow = d\n")
+	p := writeTemp(t, dir, "main.conf", "allow = 'a, b'\ninclude 'extra.conf'\nallow = e\n")
+
+	f, err := Parse(p)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	v := &lists{}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if !slices.Equal(v.Allow, []string{"a", "b", "d", "e"}) {
+		t.Errorf("Allow = %q, want definitions in file order", v.Allow)
+	}
+}
+
+func TestUnmarshalSection_SliceErrors(t *testing.T) {
+	type sepOnString struct {
+		Host string `ini:"hosts,sep=;"`
+	}
+	tests := []struct {
+		param   string
+		value   string
+		v       any
+		wantErr string
+	}{
+		{"ports", "1, two", &lists{}, "field Ports: element 1: invalid integer value"},
+		{"addrs", "10.0.0.1, nope", &lists{}, "field Addrs: element 1"},
+		{"hosts", "a,,b", &lists{}, "field Hosts: invalid list value"},
+		{"hosts", "a", &sepOnString{}, "field Host: sep and multi options require a slice field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.param, func(t *testing.T) {
+			f, err := NewIniFile(nonExistingPath("test.conf"))
+			if err != nil {
+				t.Fatalf("NewIniFile: %v", err)
+			}
+			f.GetSection("").SetParam(tt.param, tt.value)
+			err = f.UnmarshalSection("", tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// --- pointer tests ---
+
+type pointers struct {
+	Port    *int           `ini:"port"`
+	Enabled *bool          `ini:"enabled"`
+	Name    *string        `ini:"name"`
+	Timeout *time.Duration `ini:"timeout,unit=ms"`
+	Addr    *netip.Addr    `ini:"addr"`
+	Hosts   *[]string      `ini:"hosts"`
+	WorkMem *int64         `ini:"work_mem,unit=kB"`
+}
+
+func TestUnmarshalSection_Pointers(t *testing.T) {
+	f, err := ParseString("pointers.conf", strings.Join([]string{
+		"port = 0",
+		"enabled = off",
+		"timeout = 250",
+		"addr = 10.0.0.1",
+		"hosts = 'a, b'",
+		"work_mem = 4MB",
+		"",
+	}, "\n"))
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+
+	v := &pointers{}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if v.Port == nil || *v.Port != 0 {
+		t.Errorf("Port = %v, want pointer to 0", v.Port)
+	}
+	if v.Enabled == nil || *v.Enabled {
+		t.Errorf("Enabled = %v, want pointer to false", v.Enabled)
+	}
+	if v.Name != nil {
+		t.Errorf("Name = %q, want nil for an absent key", *v.Name)
+	}
+	if v.Timeout == nil || *v.Timeout != 250*time.Millisecond {
+		t.Errorf("Timeout = %v, want pointer to 250ms", v.Timeout)
+	}
+	if v.Addr == nil || *v.Addr != netip.MustParseAddr("10.0.0.1") {
+		t.Errorf("Addr = %v, want pointer to 10.0.0.1", v.Addr)
+	}
+	if v.Hosts == nil || !slices.Equal(*v.Hosts, []string{"a", "b"}) {
+		t.Errorf("Hosts = %v, want pointer to [a b]", v.Hosts)
+	}
+	if v.WorkMem == nil || *v.WorkMem != 4096 {
+		t.Errorf("WorkMem = %v, want pointer to 4096", v.WorkMem)
+	}
+}
+
+func TestUnmarshalSection_PointerKeepsPointee(t *testing.T) {
+	f, err := ParseString("pointers.conf", "port = 5432\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	shared := 1
+	v := &pointers{Port: &shared}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if *v.Port != 5432 || shared != 1 {
+		t.Errorf("Port = %d, shared = %d; want a new pointer to 5432", *v.Port, shared)
+	}
+}
+
+func TestUnmarshalSection_PointerError(t *testing.T) {
+	f, err := ParseString("pointers.conf", "port = many\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v := &pointers{}
+	err = f.UnmarshalSection("", v)
+	if err == nil || !strings.Contains(err.Error(), "field Port: invalid integer value") {
+		t.Errorf("expected invalid integer error for field Port, got %v", err)
+	}
+	if v.Port != nil {
+		t.Errorf("Port = %d, want nil after a failed decode", *v.Port)
+	}
+}
+
+func TestUnmarshalSection_PointerUnmarshalerError(t *testing.T) {
+	type colors struct {
+		Fg *color `ini:"fg"`
+		Bg *color `ini:"bg"`
+	}
+	f, err := ParseString("pointers.conf", "fg = ''\nbg = ''\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	bg := &color{name: "ini:blue"}
+	v := &colors{Bg: bg}
+	err = f.UnmarshalSection("", v)
+	if err == nil || !strings.Contains(err.Error(), "field Fg: empty color") {
+		t.Errorf("expected empty color error for field Fg, got %v", err)
+	}
+	if v.Fg != nil {
+		t.Errorf("Fg = %+v, want nil after a failed decode", *v.Fg)
+	}
+	if v.Bg != bg || bg.name != "ini:blue" {
+		t.Errorf("Bg = %+v, want the original pointer and pointee after a failed decode", *v.Bg)
+	}
+}
+
+// --- default tag tests ---
+
+type defaultsDatabase struct {
+	Host string `ini:"host" default:"localhost"`
+	Port int    `ini:"port" default:"5432"`
+}
+
+type defaultsConfig struct {
+	Name     string           `ini:"name" default:"app"`
+	Timeout  time.Duration    `ini:"timeout,unit=s" default:"30"`
+	Hosts    []string         `ini:"hosts" default:"a, b"`
+	Level    slog.Level       `ini:"level" default:"warn"`
+	Tags     []string         `ini:"tags" default:"x"`
+	Retries  *int             `ini:"retries" default:"3"`
+	Optional *int             `ini:"optional"`
+	NoTag    string           `ini:"no_tag"`
+	Database defaultsDatabase `ini:"database,section"`
+}
+
+// UnmarshalTags upper-cases each tag, to show defaults go through custom
+// methods.
+func (c *defaultsConfig) UnmarshalTags(value string) (*[]string, error) {
+	tags := strings.Split(strings.ToUpper(value), ",")
+	return &tags, nil
+}
+
+func TestUnmarshalFile_Defaults(t *testing.T) {
+	f, err := ParseString("defaults.conf", "name = custom\nhosts = ''\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+
+	v := &defaultsConfig{NoTag: "kept"}
+	if err := f.UnmarshalFile(v); err != nil {
+		t.Fatalf("UnmarshalFile: %v", err)
+	}
+	retries := 3
+	want := defaultsConfig{
+		Name:     "custom",
+		Timeout:  30 * time.Second,
+		Hosts:    []string{},
+		Level:    slog.LevelWarn,
+		Tags:     []string{"X"},
+		Retries:  &retries,
+		NoTag:    "kept",
+		Database: defaultsDatabase{Host: "localhost", Port: 5432},
+	}
+	if !reflect.DeepEqual(*v, want) {
+		t.Errorf("got %+v, want %+v", *v, want)
+	}
+}
+
+func TestUnmarshalSection_DefaultsOnlyWhenMissing(t *testing.T) {
+	f, err := ParseString("defaults.conf", "[database]\nport = 6543\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v := &defaultsDatabase{}
+	if err := f.UnmarshalSection("database", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if *v != (defaultsDatabase{Host: "localhost", Port: 6543}) {
+		t.Errorf("got %+v, want default host and file port", *v)
+	}
+}
+
+func TestUnmarshalSection_BadDefault(t *testing.T) {
+	type badDefault struct {
+		Port int `ini:"port" default:"many"`
+	}
+	f, err := NewIniFile(nonExistingPath("test.conf"))
+	if err != nil {
+		t.Fatalf("NewIniFile: %v", err)
+	}
+	err = f.UnmarshalSection("", &badDefault{})
+	if err == nil || !strings.Contains(err.Error(), `field Port: default "many": invalid integer value`) {
+		t.Errorf("expected default decode error, got %v", err)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	v, err := Defaults[defaultsConfig]()
+	if err != nil {
+		t.Fatalf("Defaults: %v", err)
+	}
+	if v.Name != "app" || v.Timeout != 30*time.Second || !slices.Equal(v.Hosts, []string{"a", "b"}) {
+		t.Errorf("got %+v, want defaults", *v)
+	}
+	if v.Retries == nil || *v.Retries != 3 || v.Optional != nil {
+		t.Errorf("Retries = %v, Optional = %v; want 3 and nil", v.Retries, v.Optional)
+	}
+	if v.Database != (defaultsDatabase{Host: "localhost", Port: 5432}) {
+		t.Errorf("Database = %+v, want section defaults", v.Database)
+	}
+
+	if _, err := Defaults[int](); err == nil {
+		t.Error("expected error for non-struct type")
+	}
+}
+
+// --- required and strict tests ---
+
+type strictDatabase struct {
+	Host string `ini:"host,required"`
+	Port int    `ini:"Port"`
+}
+
+type strictConfig struct {
+	Name     string                     `ini:"name,required"`
+	Database strictDatabase             `ini:"database,section"`
+	Replicas map[string]*strictDatabase `ini:"replica_*,sections"`
+}
+
+func TestUnmarshalSection_Required(t *testing.T) {
+	f, err := ParseString("strict.conf", "[database]\nport = 5432\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v := &strictDatabase{}
+	err = f.UnmarshalSection("database", v)
+	if err == nil || !strings.Contains(err.Error(), `section "database": key "host": field Host: required key is missing`) {
+		t.Errorf("expected required error, got %v", err)
+	}
+	if v.Port != 5432 {
+		t.Errorf("Port = %d, want fields after the error still decoded", v.Port)
+	}
+}
+
+func TestUnmarshalSection_Strict(t *testing.T) {
+	dir := t.TempDir()
+	p := writeTemp(t, dir, "strict.conf", "[database]\nhost = db\nprot = 5432\nPORT = 6543\nextra = 1\n")
+	f, err := Parse(p)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	v := &strictDatabase{}
+	if err := f.UnmarshalSection("database", v); err != nil {
+		t.Fatalf("UnmarshalSection without WithStrict: %v", err)
+	}
+
+	err = f.UnmarshalSection("database", v, WithStrict())
+	if err == nil {
+		t.Fatal("expected unknown key errors")
+	}
+	for _, want := range []string{
+		p + `:3:1: section "database": key "prot": unknown key`,
+		p + `:5:1: section "database": key "extra": unknown key`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should contain %q, got:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), `"port"`) {
+		t.Errorf("port is consumed by a field, got:\n%v", err)
+	}
+	if v.Port != 6543 {
+		t.Errorf("Port = %d, want known keys still decoded", v.Port)
+	}
+}
+
+func TestUnmarshalFile_StrictAggregates(t *testing.T) {
+	f, err := ParseString("strict.conf", strings.Join([]string{
+		"nmae = app",
+		"",
+		"[database]",
+		"port = 5432",
+		"",
+		"[replica_a]",
+		"host = r1",
+		"hots = typo",
+		"",
+		"[cache]",
+		"size = 1",
+		"",
+	}, "\n"))
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+
+	err = f.UnmarshalFile(&strictConfig{}, WithStrict())
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	want := []string{
+		`key "name": field Name: required key is missing`,
+		`strict.conf:1:1: key "nmae": unknown key`,
+		`section "database": key "host": field Host: required key is missing`,
+		`strict.conf:8:1: section "replica_a": key "hots": unknown key`,
+		`strict.conf:10:1: section "cache": unknown section`,
+	}
+	got := strings.Split(strings.TrimPrefix(err.Error(), "UnmarshalFile: "), "\n")
+	if !slices.Equal(got, want) {
+		t.Errorf("errors mismatch\n--- got ---\n%s\n--- want ---\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDefaults_IgnoresRequired(t *testing.T) {
+	v, err := Defaults[strictConfig]()
+	if err != nil {
+		t.Fatalf("Defaults: %v", err)
+	}
+	if v.Name != "" {
+		t.Errorf("Name = %q, want zero value", v.Name)
+	}
+}
+
+// --- embedded and inline struct tests ---
+
+type inlineTLS struct {
+	CertFile string `ini:"cert_file"`
+	Mode     string `ini:"mode" default:"require"`
+	Port     int    `ini:"port"`
+}
+
+// UnmarshalCertFile is found on the struct that declares the field.
+func (t *inlineTLS) UnmarshalCertFile(value string) (*string, error) {
+	s := strings.TrimPrefix(value, "file:")
+	return &s, nil
+}
+
+type inlineDatabase struct {
+	inlineTLS
+	Host    string     `ini:"host"`
+	Replica *inlineTLS `ini:",inline,prefix=replica_"`
+}
+
+type inlineCache struct {
+	Host string    `ini:"host"`
+	TLS  inlineTLS `ini:",inline,prefix=tls_"`
+}
+
+type inlineConfig struct {
+	Database inlineDatabase `ini:"database,section"`
+	Cache    inlineCache    `ini:"cache,section"`
+}
+
+func TestUnmarshalFile_Inline(t *testing.T) {
+	f, err := ParseString("inline.conf", strings.Join([]string{
+		"[database]",
+		"host = db",
+		"cert_file = 'file:/etc/db.pem'",
+		"port = 5432",
+		"",
+		"[cache]",
+		"host = cache",
+		"tls_cert_file = /etc/cache.pem",
+		"tls_mode = verify-full",
+		"",
+	}, "\n"))
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+
+	v := &inlineConfig{}
+	if err := f.UnmarshalFile(v, WithStrict()); err != nil {
+		t.Fatalf("UnmarshalFile: %v", err)
+	}
+	want := inlineConfig{
+		Database: inlineDatabase{
+			inlineTLS: inlineTLS{CertFile: "/etc/db.pem", Mode: "require", Port: 5432},
+			Host:      "db",
+		},
+		Cache: inlineCache{
+			Host: "cache",
+			TLS:  inlineTLS{CertFile: "/etc/cache.pem", Mode: "verify-full"},
+		},
+	}
+	if v.Database.Replica != nil {
+		t.Errorf("Replica = %+v, want nil without replica_ keys", v.Database.Replica)
+	}
+	v.Database.Replica = nil
+	if *v != want {
+		t.Errorf("got %+v, want %+v", *v, want)
+	}
+}
+
+func TestUnmarshalSection_InlinePointer(t *testing.T) {
+	f, err := ParseString("inline.conf", "replica_port = 6432\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v := &inlineDatabase{}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if v.Replica == nil || *v.Replica != (inlineTLS{Mode: "require", Port: 6432}) {
+		t.Errorf("Replica = %+v, want port 6432 and the default mode", v.Replica)
+	}
+}
+
+func TestUnmarshalSection_InlineError(t *testing.T) {
+	f, err := ParseString("inline.conf", "tls_port = many\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	derrs := requireDecodeErrors(t, f.UnmarshalSection("", &inlineCache{}))
+	if len(derrs) != 1 || derrs[0].Key != "tls_port" || derrs[0].Field != "TLS.Port" {
+		t.Errorf("got %v, want one error for key tls_port and field TLS.Port", derrs)
+	}
+}
+
+// --- remain field and map target tests ---
+
+type remainPassthrough struct {
+	Host    string            `ini:"host"`
+	Port    int               `ini:"port"`
+	Options map[string]string `ini:",remain"`
+}
+
+const remainConf = `host = db
+sslmode = require
+port = 5432
+Application_Name = 'app'
+`
+
+func TestUnmarshalSection_Remain(t *testing.T) {
+	f, err := ParseString("remain.conf", remainConf)
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v := &remainPassthrough{}
+	if err := f.UnmarshalSection("", v, WithStrict()); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	want := map[string]string{"sslmode": "require", "application_name": "app"}
+	if v.Host != "db" || v.Port != 5432 || !reflect.DeepEqual(v.Options, want) {
+		t.Errorf("got %+v, want host, port, and Options %v", *v, want)
+	}
+}
+
+func TestUnmarshalSection_RemainKeepsEntries(t *testing.T) {
+	f, err := ParseString("remain.conf", "host = db\nsslmode = require\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v := &remainPassthrough{Options: map[string]string{"sslmode": "disable", "connect_timeout": "10"}}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	want := map[string]string{"sslmode": "require", "connect_timeout": "10"}
+	if !reflect.DeepEqual(v.Options, want) {
+		t.Errorf("Options = %v, want %v", v.Options, want)
+	}
+}
+
+func TestUnmarshalSection_RemainEmpty(t *testing.T) {
+	f, err := ParseString("remain.conf", "host = db\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v := &remainPassthrough{}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if v.Options != nil {
+		t.Errorf("Options = %v, want nil when every key is claimed", v.Options)
+	}
+}
+
+func TestUnmarshalSection_RemainAny(t *testing.T) {
+	type labeled struct {
+		Name   string         `ini:"name"`
+		Labels map[string]any `ini:",remain"`
+	}
+	f, err := ParseString("remain.conf", "name = web\ntier = frontend\nreplicas = 3\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v := &labeled{}
+	if err := f.UnmarshalSection("", v); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	want := map[string]any{"tier": "frontend", "replicas": "3"}
+	if !reflect.DeepEqual(v.Labels, want) {
+		t.Errorf("Labels = %v, want %v", v.Labels, want)
+	}
+}
+
+func TestUnmarshalSection_Map(t *testing.T) {
+	f, err := ParseString("remain.conf", "[labels]\nTeam = db\ntier = backend\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+
+	var labels map[string]string
+	if err := f.UnmarshalSection("labels", &labels); err != nil {
+		t.Fatalf("UnmarshalSection: %v", err)
+	}
+	if want := map[string]string{"team": "db", "tier": "backend"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("labels = %v, want %v", labels, want)
+	}
+
+	anyLabels, err := LoadReader[map[string]any]("remain.conf", strings.NewReader("[labels]\ntier = backend\n"), "labels")
+	if err != nil {
+		t.Fatalf("LoadReader: %v", err)
+	}
+	if want := map[string]any{"tier": "backend"}; !reflect.DeepEqual(*anyLabels, want) {
+		t.Errorf("labels = %v, want %v", *anyLabels, want)
+	}
+}
+
+func TestUnmarshalSection_MapTargetErrors(t *testing.T) {
+	f, err := ParseString("remain.conf", "tier = backend\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	var nilMap *map[string]string
+	for _, target := range []any{map[string]string{}, &map[string]int{}, nilMap} {
+		err := f.UnmarshalSection("", target)
+		if err == nil || !strings.Contains(err.Error(), "pointer to a struct or to a map[string]string") {
+			t.Errorf("UnmarshalSection(%T) error = %v, want a target type error", target, err)
+		}
+	}
+}
+
 // --- parseBool tests ---
 
 func TestParseBool(t *testing.T) {
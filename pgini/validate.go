@@ -0,0 +1,355 @@
+// Validation checks decoded values against a `validate:"RULE,..."` struct
+// tag. Rules are separated by commas; a regex rule must come last, since it
+// takes the rest of the tag, commas included.
+//
+//   - min=N, max=N: bounds on a number, time.Duration, or memory size, where
+//     N is written as the field's value would be (such as "1s" or "64MB"), or
+//     bounds on the length of a string, slice, or map
+//   - oneof=A B C: the value is one of the space-separated values
+//   - nonempty: a string, slice, or map is not empty; any other value is not
+//     its zero value
+//   - port: an integer from 1 to 65535
+//   - path_exists: a file or directory exists at the path; a relative path
+//     resolves against the directory of the file that set it, and a path set
+//     by a file read from an fs.FS resolves within it, as includes do
+//   - url: an absolute URL, with a scheme
+//   - regex=RE: a string matches the regular expression RE
+//
+// On a slice field, min, max, and nonempty apply to its length, and the other
+// rules to each element. Nil pointer fields are not validated. Failures are
+// reported as a DecodeError naming the section, key, field, and the file and
+// line that set the value.
+//
+// After a struct is decoded without errors, its Validate method is called if
+// it implements Validator.
+
+package pgini
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator is implemented by structs that check themselves after decoding.
+// UnmarshalSection, UnmarshalFile, and UnmarshalSections call Validate on
+// each struct they decode, including nested section structs, once its fields
+// have decoded without errors.
+type Validator interface {
+	Validate() error
+}
+
+// validateRule is one rule of a `validate` struct tag.
+type validateRule struct {
+	// name is the rule name, such as "min".
+	name string
+	// arg is the text after '=', or empty.
+	arg string
+	// re is the compiled expression of a regex rule.
+	re *regexp.Regexp
+	// length is the bound of a min or max rule on the length of a string,
+	// slice, or map.
+	length int
+	// bound is the bound of a min or max rule on a number, decoded as the
+	// field's value would be.
+	bound reflect.Value
+	// options are the values of a oneof rule, decoded as the field's value,
+	// or a slice field's element, would be.
+	options []reflect.Value
+}
+
+// parseValidateTag parses the value of a `validate` struct tag.
+func parseValidateTag(tag string) ([]validateRule, error) {
+	var rules []validateRule
+	for rest := tag; rest != ""; {
+		var item string
+		if strings.HasPrefix(strings.TrimSpace(rest), "regex=") {
+			item, rest = rest, ""
+		} else {
+			item, rest, _ = strings.Cut(rest, ",")
+		}
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, arg, hasArg := strings.Cut(item, "=")
+		r := validateRule{name: name, arg: arg}
+		switch name {
+		case "min", "max", "oneof":
+			if !hasArg || strings.TrimSpace(arg) == "" {
+				return nil, fmt.Errorf("invalid validate tag %q: %s needs a value", tag, name)
+			}
+		case "regex":
+			re, err := regexp.Compile(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid validate tag %q: %w", tag, err)
+			}
+			r.re = re
+		case "nonempty", "port", "path_exists", "url":
+			if hasArg {
+				return nil, fmt.Errorf("invalid validate tag %q: %s takes no value", tag, name)
+			}
+		default:
+			return nil, fmt.Errorf("invalid validate tag %q: unknown rule %q", tag, name)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// compileRules decodes the arguments of the min, max, and oneof rules of a
+// field of type t against that type, with ft holding the field's options, so
+// that a bad argument is reported with the other tag errors. tag is the
+// `validate` tag the rules were parsed from.
+func compileRules(tag string, rules []validateRule, t reflect.Type, ft fieldTag) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	elemType, elemTag := t, ft
+	if t.Kind() == reflect.Slice {
+		elemType, elemTag = t.Elem(), fieldTag{name: ft.name, unit: ft.unit}
+	}
+
+	for i := range rules {
+		r := &rules[i]
+		switch r.name {
+		case "min", "max":
+			switch t.Kind() {
+			case reflect.String, reflect.Slice, reflect.Map:
+				n, err := strconv.Atoi(r.arg)
+				if err != nil {
+					return fmt.Errorf("invalid validate tag %q: invalid %s length %q", tag, r.name, r.arg)
+				}
+				r.length = n
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+				reflect.Float32, reflect.Float64:
+				r.bound = reflect.New(t).Elem()
+				if err := unmarshalValue(r.bound, &Param{Name: ft.name, Value: r.arg}, ft); err != nil {
+					return fmt.Errorf("invalid validate tag %q: invalid %s value %q: %w", tag, r.name, r.arg, err)
+				}
+			default:
+				return fmt.Errorf("invalid validate tag %q: %s rule does not apply to %s", tag, r.name, t)
+			}
+		case "oneof":
+			for _, opt := range strings.Fields(r.arg) {
+				want := reflect.New(elemType).Elem()
+				if err := unmarshalValue(want, &Param{Name: ft.name, Value: opt}, elemTag); err != nil {
+					return fmt.Errorf("invalid validate tag %q: invalid oneof value %q: %w", tag, opt, err)
+				}
+				r.options = append(r.options, want)
+			}
+		}
+	}
+	return nil
+}
+
+// validateField checks fieldValue against the rules of field, returning the
+// reasons it fails as one error. param is the definition the value was
+// decoded from, or nil when the key was missing, and fsys is the filesystem
+// the file was read from, or nil for the OS.
+func validateField(field taggedField, fieldValue reflect.Value, param *Param, fsys fs.FS) error {
+	var origin Location
+	if param != nil {
+		origin = param.Origin
+	}
+	for fieldValue.Kind() == reflect.Pointer {
+		if fieldValue.IsNil() {
+			return nil
+		}
+		fieldValue = fieldValue.Elem()
+	}
+
+	elemTag := fieldTag{name: field.tag.name, unit: field.tag.unit}
+	var msgs []string
+	for _, r := range field.rules {
+		var err error
+		switch {
+		case r.name == "min" || r.name == "max" || r.name == "nonempty":
+			err = checkRule(r, fieldValue, field.tag, origin, fsys)
+		case fieldValue.Kind() == reflect.Slice:
+			for i := range fieldValue.Len() {
+				if err = checkRule(r, fieldValue.Index(i), elemTag, origin, fsys); err != nil {
+					err = fmt.Errorf("element %d: %w", i, err)
+					break
+				}
+			}
+		default:
+			err = checkRule(r, fieldValue, field.tag, origin, fsys)
+		}
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// checkRule checks v against r, whose arguments compileRules has decoded. tag
+// formats v for messages, and origin and fsys resolve paths.
+func checkRule(r validateRule, v reflect.Value, tag fieldTag, origin Location, fsys fs.FS) error {
+	switch r.name {
+	case "min", "max":
+		return checkBound(r, v, tag)
+	case "oneof":
+		for _, want := range r.options {
+			if reflect.DeepEqual(v.Interface(), want.Interface()) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s is not one of %s", describeValue(v, tag), strings.Join(strings.Fields(r.arg), ", "))
+	case "nonempty":
+		switch v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map:
+			if v.Len() == 0 {
+				return fmt.Errorf("value is empty")
+			}
+		default:
+			if v.IsZero() {
+				return fmt.Errorf("value is empty")
+			}
+		}
+	case "port":
+		var n int64
+		switch {
+		case v.CanInt():
+			n = v.Int()
+		case v.CanUint() && v.Uint() <= 65535:
+			n = int64(v.Uint())
+		case v.CanUint():
+			n = -1
+		default:
+			return fmt.Errorf("port rule requires an integer field, got %s", v.Type())
+		}
+		if n < 1 || n > 65535 {
+			return fmt.Errorf("%s is not a port number from 1 to 65535", describeValue(v, tag))
+		}
+	case "path_exists":
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("path_exists rule requires a string field, got %s", v.Type())
+		}
+		if !pathExists(v.String(), origin, fsys) {
+			return fmt.Errorf("path %q does not exist", v.String())
+		}
+	case "url":
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("url rule requires a string field, got %s", v.Type())
+		}
+		if u, err := url.Parse(v.String()); err != nil || u.Scheme == "" {
+			return fmt.Errorf("%q is not an absolute URL", v.String())
+		}
+	case "regex":
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("regex rule requires a string field, got %s", v.Type())
+		}
+		if !r.re.MatchString(v.String()) {
+			return fmt.Errorf("%q does not match %s", v.String(), r.re)
+		}
+	}
+	return nil
+}
+
+// pathExists reports whether a file or directory exists at p, a path set at
+// origin. When origin is a file read from fsys, p resolves within fsys: a
+// relative path against the directory of origin and an absolute path against
+// the root of fsys. Otherwise a relative path resolves against the directory
+// of origin, if it is a file, on the OS filesystem.
+func pathExists(p string, origin Location, fsys fs.FS) bool {
+	if fsys != nil && origin.Path != "" {
+		if path.IsAbs(p) {
+			p = strings.TrimPrefix(path.Clean(p), "/")
+		} else {
+			p = path.Join(path.Dir(origin.Path), p)
+		}
+		if p == "" {
+			p = "."
+		}
+		if !fs.ValidPath(p) {
+			return false
+		}
+		_, err := fs.Stat(fsys, p)
+		return err == nil
+	}
+
+	if !filepath.IsAbs(p) && origin.Path != "" {
+		p = filepath.Join(filepath.Dir(origin.Path), p)
+	}
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+// checkBound checks v against a min or max rule: the length of a string,
+// slice, or map, or else the value itself.
+func checkBound(r validateRule, v reflect.Value, tag fieldTag) error {
+	var order int
+	var got string
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		length := v.Len()
+		if v.Kind() == reflect.String {
+			length = utf8.RuneCountInString(v.String())
+		}
+		order = cmp.Compare(length, r.length)
+		got = fmt.Sprintf("length %d", length)
+	default:
+		switch {
+		case v.CanInt():
+			order = cmp.Compare(v.Int(), r.bound.Int())
+		case v.CanUint():
+			order = cmp.Compare(v.Uint(), r.bound.Uint())
+		default:
+			order = cmp.Compare(v.Float(), r.bound.Float())
+		}
+		got = describeValue(v, tag)
+	}
+
+	if r.name == "min" && order < 0 {
+		return fmt.Errorf("%s is less than min %s", got, r.arg)
+	}
+	if r.name == "max" && order > 0 {
+		return fmt.Errorf("%s is greater than max %s", got, r.arg)
+	}
+	return nil
+}
+
+// describeValue formats v for messages as it would be written to a file.
+func describeValue(v reflect.Value, tag fieldTag) string {
+	if str, err := marshalValue(v, tag); err == nil {
+		if v.Kind() == reflect.String {
+			return strconv.Quote(v.String())
+		}
+		return str
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// validateStruct calls the Validate method of structValue, an addressable
+// struct decoded from the named section, if it or its pointer implements
+// Validator. A failure is returned as a DecodeErrors.
+func validateStruct(section string, structValue reflect.Value) error {
+	var target any = structValue.Interface()
+	if structValue.CanAddr() {
+		target = structValue.Addr().Interface()
+	}
+	v, ok := target.(Validator)
+	if !ok {
+		return nil
+	}
+	if err := v.Validate(); err != nil {
+		return DecodeErrors{{Section: section, Type: structValue.Type(), Err: err}}
+	}
+	return nil
+}
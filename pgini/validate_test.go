@@ -0,0 +1,354 @@
+package pgini
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// --- parseValidateTag tests ---
+
+func TestParseValidateTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want []string // rule names and args, as "name=arg"
+	}{
+		{"", nil},
+		{"port", []string{"port="}},
+		{"min=1, max=10", []string{"min=1", "max=10"}},
+		{"nonempty,oneof=a b c", []string{"nonempty=", "oneof=a b c"}},
+		{"nonempty,regex=^[a-z]{1,3}$", []string{"nonempty=", "regex=^[a-z]{1,3}$"}},
+		{"url,", []string{"url="}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			rules, err := parseValidateTag(tt.tag)
+			if err != nil {
+				t.Fatalf("parseValidateTag(%q): %v", tt.tag, err)
+			}
+			var got []string
+			for _, r := range rules {
+				got = append(got, r.name+"="+r.arg)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("parseValidateTag(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseValidateTag_Errors(t *testing.T) {
+	tests := []struct {
+		tag     string
+		wantErr string
+	}{
+		{"between=1", `unknown rule "between"`},
+		{"min", "min needs a value"},
+		{"oneof= ", "oneof needs a value"},
+		{"port=80", "port takes no value"},
+		{"regex=[", "missing closing ]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			_, err := parseValidateTag(tt.tag)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseValidateTag(%q) error = %v, want %q", tt.tag, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// --- validation rule tests ---
+
+type validated struct {
+	Port     int           `ini:"port" validate:"port"`
+	Workers  uint8         `ini:"workers" validate:"min=1,max=16"`
+	Ratio    float64       `ini:"ratio" validate:"min=0, max=1"`
+	Timeout  time.Duration `ini:"timeout" validate:"min=1s,max=5min"`
+	WorkMem  int           `ini:"work_mem,unit=kB" validate:"max=64MB"`
+	Mode     string        `ini:"mode" validate:"oneof=fast safe"`
+	Name     string        `ini:"name" validate:"nonempty,max=8,regex=^[a-z_]+$"`
+	Hosts    []string      `ini:"hosts" validate:"min=1,regex=^[a-z0-9.]+$"`
+	Endpoint string        `ini:"endpoint" validate:"url"`
+	DataDir  string        `ini:"data_dir" validate:"path_exists"`
+	Optional *int          `ini:"optional" validate:"port"`
+}
+
+// validConf is a file for validated that passes every rule.
+const validConf = `port = 5432
+workers = 4
+ratio = 0.5
+timeout = 30s
+work_mem = 4MB
+mode = safe
+name = app
+hosts = 'a.example, b.example'
+endpoint = https://example.com/api
+data_dir = .
+`
+
+func TestValidate_Valid(t *testing.T) {
+	dir := t.TempDir()
+	p := writeTemp(t, dir, "valid.conf", validConf)
+	if _, err := Load[validated](p, ""); err != nil {
+		t.Errorf("Load: %v", err)
+	}
+}
+
+func TestValidate_Rules(t *testing.T) {
+	tests := []struct {
+		line    string
+		wantErr string
+	}{
+		{"port = 0", `key "port": field Port: 0 is not a port number from 1 to 65535`},
+		{"port = 70000", `key "port": field Port: 70000 is not a port number`},
+		{"workers = 0", `key "workers": field Workers: 0 is less than min 1`},
+		{"workers = 17", `key "workers": field Workers: 17 is greater than max 16`},
+		{"ratio = 1.5", `key "ratio": field Ratio: 1.5 is greater than max 1`},
+		{"timeout = 500ms", `key "timeout": field Timeout: 500ms is less than min 1s`},
+		{"timeout = 1h", `key "timeout": field Timeout: 1h is greater than max 5min`},
+		{"work_mem = 1GB", `key "work_mem": field WorkMem: 1GB is greater than max 64MB`},
+		{"mode = turbo", `key "mode": field Mode: "turbo" is not one of fast, safe`},
+		{"name = ''", `key "name": field Name: value is empty; "" does not match ^[a-z_]+$`},
+		{"name = much_too_long", `key "name": field Name: length 13 is greater than max 8`},
+		{"hosts = ''", `key "hosts": field Hosts: length 0 is less than min 1`},
+		{"hosts = 'ok, NOT_OK'", `key "hosts": field Hosts: element 1: "NOT_OK" does not match`},
+		{"endpoint = example.com", `key "endpoint": field Endpoint: "example.com" is not an absolute URL`},
+		{"data_dir = missing_dir", `key "data_dir": field DataDir: path "missing_dir" does not exist`},
+		{"optional = 0", `key "optional": field Optional: 0 is not a port number`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			dir := t.TempDir()
+			p := writeTemp(t, dir, "app.conf", validConf+tt.line+"\n")
+			_, err := Load[validated](p, "")
+			// The extra line is line 11 of the file.
+			want := p + ":11:1: " + tt.wantErr
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("expected error containing %q, got %v", want, err)
+			}
+		})
+	}
+}
+
+func TestValidate_PathRelativeToFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTemp(t, dir, "data/keep", "")
+	p := writeTemp(t, dir, "app.conf", validConf+"data_dir = data\n")
+	t.Chdir(t.TempDir())
+	if _, err := Load[validated](p, ""); err != nil {
+		t.Errorf("Load: %v", err)
+	}
+}
+
+func TestValidate_PathWithinFS(t *testing.T) {
+	type paths struct {
+		DataDir string `ini:"data_dir" validate:"path_exists"`
+	}
+	fsys := fstest.MapFS{
+		"app/app.conf":    {Data: []byte("include 'dir.conf'\n")},
+		"app/data/keep":   {},
+		"shared/certs/ca": {},
+	}
+	t.Chdir(t.TempDir())
+
+	tests := []struct {
+		dirConf string
+		wantErr string
+	}{
+		{"data_dir = data", ""},
+		{"data_dir = /shared/certs", ""},
+		{"data_dir = ../shared/certs/ca", ""},
+		{"data_dir = missing", `path "missing" does not exist`},
+		{"data_dir = ../../etc", `path "../../etc" does not exist`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dirConf, func(t *testing.T) {
+			fsys["app/dir.conf"] = &fstest.MapFile{Data: []byte(tt.dirConf + "\n")}
+			_, err := LoadFS[paths](fsys, "app/app.conf", "")
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("LoadFS: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), "app/dir.conf:1:1: key \"data_dir\": field DataDir: "+tt.wantErr)):
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_SectionAndMissingKey(t *testing.T) {
+	type limits struct {
+		Workers int `ini:"workers" validate:"min=1"`
+	}
+	f, err := ParseString("app.conf", "[limits]\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	err = f.UnmarshalSection("limits", &limits{})
+	want := `section "limits": key "workers": field Workers: 0 is less than min 1`
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("expected error containing %q, got %v", want, err)
+	}
+}
+
+func TestValidate_BadTag(t *testing.T) {
+	type bad struct {
+		Port int `ini:"port" validate:"between=1"`
+	}
+	f, err := ParseString("app.conf", "port = 1\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	err = f.UnmarshalSection("", &bad{})
+	if err == nil || !strings.Contains(err.Error(), `field Port: invalid validate tag`) {
+		t.Errorf("expected tag error, got %v", err)
+	}
+}
+
+func TestTaggedFields_ValidateArgErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     reflect.Type
+		wantErr string
+	}{
+		{"integer bound", reflect.TypeFor[struct {
+			Workers int `ini:"workers" validate:"min=one"`
+		}](), `field Workers: invalid validate tag "min=one": invalid min value "one"`},
+		{"duration bound", reflect.TypeFor[struct {
+			Timeout time.Duration `ini:"timeout" validate:"max=5x"`
+		}](), `field Timeout: invalid validate tag "max=5x": invalid max value "5x"`},
+		{"memory bound", reflect.TypeFor[struct {
+			WorkMem int `ini:"work_mem,unit=kB" validate:"max=64ms"`
+		}](), `field WorkMem: invalid validate tag "max=64ms": invalid max value "64ms"`},
+		{"length bound", reflect.TypeFor[struct {
+			Hosts []string `ini:"hosts" validate:"min=1s"`
+		}](), `field Hosts: invalid validate tag "min=1s": invalid min length "1s"`},
+		{"bound on bool", reflect.TypeFor[struct {
+			Debug *bool `ini:"debug" validate:"min=1"`
+		}](), `field Debug: invalid validate tag "min=1": min rule does not apply to bool`},
+		{"oneof value", reflect.TypeFor[struct {
+			Level int `ini:"level" validate:"oneof=1 two"`
+		}](), `field Level: invalid validate tag "oneof=1 two": invalid oneof value "two"`},
+		{"oneof element", reflect.TypeFor[struct {
+			Ports []int `ini:"ports" validate:"oneof=80 http"`
+		}](), `field Ports: invalid validate tag "oneof=80 http": invalid oneof value "http"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := taggedFields(tt.typ)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// The tag error is reported even when the key is missing.
+	type bad struct {
+		Workers int `ini:"workers" validate:"min=one"`
+	}
+	f, err := ParseString("app.conf", "")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	err = f.UnmarshalSection("", &bad{})
+	var derrs DecodeErrors
+	if err == nil || errors.As(err, &derrs) || !strings.Contains(err.Error(), "invalid min value") {
+		t.Errorf("expected a tag error, got %v", err)
+	}
+}
+
+// --- Validator tests ---
+
+type checkedDatabase struct {
+	Primary int `ini:"primary"`
+	Replica int `ini:"replica"`
+}
+
+func (d checkedDatabase) Validate() error {
+	if d.Primary == d.Replica {
+		return errors.New("primary and replica ports must differ")
+	}
+	return nil
+}
+
+type checkedConfig struct {
+	Name     string                      `ini:"name"`
+	Database checkedDatabase             `ini:"database,section"`
+	Others   map[string]*checkedDatabase `ini:"other_*,sections"`
+	calls    int
+}
+
+func (c *checkedConfig) Validate() error {
+	c.calls++
+	if c.Name == "" {
+		return errors.New("name is required once the file is loaded")
+	}
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    string
+		wantErr string
+	}{
+		{"valid", "name = app\n[database]\nprimary = 1\nreplica = 2\n", ""},
+		{"top level", "[database]\nprimary = 1\nreplica = 2\n", "UnmarshalFile: name is required"},
+		{"section", "name = app\n[database]\nprimary = 1\nreplica = 1\n", `section "database": primary and replica ports must differ`},
+		{"sections", "name = app\n[database]\nreplica = 1\n[other_a]\n", `section "other_a": primary and replica ports must differ`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseString("app.conf", tt.conf)
+			if err != nil {
+				t.Fatalf("ParseString: %v", err)
+			}
+			v := &checkedConfig{}
+			err = f.UnmarshalFile(v)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("UnmarshalFile: %v", err)
+				}
+				if v.calls != 1 {
+					t.Errorf("Validate called %d times, want once", v.calls)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidator_SkippedAfterErrors(t *testing.T) {
+	f, err := ParseString("app.conf", "[database]\nprimary = x\nreplica = x\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	v := &checkedConfig{}
+	err = f.UnmarshalFile(v)
+	if err == nil || strings.Contains(err.Error(), "must differ") || strings.Contains(err.Error(), "name is required") {
+		t.Errorf("expected only decode errors, got %v", err)
+	}
+	if v.calls != 0 {
+		t.Errorf("Validate called %d times, want 0 after decode errors", v.calls)
+	}
+}
+
+func TestValidator_UnmarshalSection(t *testing.T) {
+	f, err := ParseString("app.conf", "[database]\nprimary = 1\nreplica = 1\n")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	err = f.UnmarshalSection("database", &checkedDatabase{})
+	if err == nil || !strings.Contains(err.Error(), "must differ") {
+		t.Errorf("expected Validate error, got %v", err)
+	}
+	if _, err := UnmarshalSections[checkedDatabase](f); err == nil {
+		t.Error("expected Validate error from UnmarshalSections")
+	}
+}